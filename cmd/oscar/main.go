@@ -4,13 +4,29 @@ package main
 import (
 	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	icli "github.com/opensourcecorp/oscar/internal/cli"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 )
 
 func main() {
-	if err := icli.NewRootCmd().Run(context.Background(), os.Args); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// An interrupt cancels the run instead of exiting right away, so that oscar can stop what it's
+	// running & clean up after itself. A second one exits right away, as usual.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		signal.Stop(signals)
+		iprint.Warnf("\nReceived %s, so stopping & cleaning up -- send it again to exit right away\n", sig)
+		cancel()
+	}()
+
+	if err := icli.NewRootCmd().Run(ctx, os.Args); err != nil {
 		iprint.Errorf("running: %v\n", err)
 		os.Exit(1)
 	}
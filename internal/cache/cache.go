@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A Cache is a directory of entries for Tasks that have passed, keyed by [Cache.Key].
+type Cache struct {
+	// The directory that entries are stored in.
+	dir string
+	// Mixed into every key, e.g. so that changing oscar's tooling invalidates every entry.
+	salt string
+}
+
+// An Entry holds information about a cached Task result. Only the existence of an entry matters
+// for cache hits -- its contents are just for anyone inspecting the cache directory.
+type Entry struct {
+	// The identifier of the Task that passed, e.g. "Go :: Build".
+	TaskID string `json:"task_id"`
+	// When the Task passed.
+	PassedAt time.Time `json:"passed_at"`
+}
+
+// New returns a [Cache] that stores its entries in dir. Any provided salts are mixed into every key,
+// and should be anything that could change the outcome of any Task (like the versions of the tools
+// that oscar runs).
+func New(dir string, salts ...[]byte) *Cache {
+	h := sha256.New()
+	for _, salt := range salts {
+		writeField(h, salt)
+	}
+
+	return &Cache{
+		dir:  dir,
+		salt: hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+// Key returns the cache key for the provided parts, which together should identify a Task and all
+// of its inputs. The order of the parts matters.
+func (c *Cache) Key(parts ...string) string {
+	h := sha256.New()
+	writeField(h, []byte(c.salt))
+	for _, part := range parts {
+		writeField(h, []byte(part))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Has reports whether there is an entry for the provided key. Hits also update the entry's
+// modification time, so that [Prune] can tell which entries are still being used.
+func (c *Cache) Has(key string) bool {
+	path := c.entryPath(key)
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+
+	now := time.Now()
+	// NOTE: a failure here only makes the entry look older than it is, so it's not worth failing for
+	_ = os.Chtimes(path, now, now)
+
+	return true
+}
+
+// Store writes an entry for the provided key.
+func (c *Cache) Store(key string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshalling cache entry: %w", err)
+	}
+
+	path := c.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// entryPath returns the path to the entry file for the provided key. Entries are spread across
+// subdirectories by the first two characters of their key, so that no single directory gets too
+// large.
+func (c *Cache) entryPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Prune removes every entry in the cache directory dir that hasn't been used (see [Cache.Has]) in
+// at least olderThan, and returns how many were removed. An olderThan of zero removes every entry.
+func Prune(dir string, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if olderThan > 0 && info.ModTime().After(cutoff) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return removed, fmt.Errorf("pruning cache directory '%s': %w", dir, err)
+	}
+
+	return removed, nil
+}
+
+// HashFiles returns a hash of the paths & contents of the provided files, which are relative to
+// root. The order of the files matters.
+func HashFiles(root string, files []string) (string, error) {
+	h := sha256.New()
+	for _, file := range files {
+		fileHash, err := hashFile(filepath.Join(root, file))
+		if err != nil {
+			return "", err
+		}
+		writeField(h, []byte(filepath.ToSlash(file)))
+		writeField(h, fileHash)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile returns the hash of a single file's contents.
+func hashFile(path string) (out []byte, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file for hashing: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("closing file '%s': %w", path, closeErr))
+		}
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("hashing file '%s': %w", path, err)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// writeField writes data to the hash along with its length, so that e.g. the parts "ab" & "c" can't
+// hash the same as the parts "a" & "bc".
+func writeField(w io.Writer, data []byte) {
+	// NOTE: hash.Hash writes never return an error
+	_, _ = fmt.Fprintf(w, "%d:", len(data))
+	_, _ = w.Write(data)
+}
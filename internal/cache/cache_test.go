@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	c := New(t.TempDir(), []byte("tools-v1"))
+
+	assert.Equal(t, c.Key("Go :: Build", "abc"), c.Key("Go :: Build", "abc"))
+	assert.NotEqual(t, c.Key("Go :: Build", "abc"), c.Key("Go :: Vet", "abc"))
+	// Parts are length-prefixed, so they can't run into each other
+	assert.NotEqual(t, c.Key("ab", "c"), c.Key("a", "bc"))
+	// Changing the salt changes every key
+	other := New(t.TempDir(), []byte("tools-v2"))
+	assert.NotEqual(t, c.Key("Go :: Build", "abc"), other.Key("Go :: Build", "abc"))
+}
+
+func TestStoreAndHas(t *testing.T) {
+	c := New(t.TempDir())
+	key := c.Key("Go :: Build")
+
+	assert.False(t, c.Has(key))
+	require.NoError(t, c.Store(key, Entry{TaskID: "Go :: Build", PassedAt: time.Now()}))
+	assert.True(t, c.Has(key))
+	assert.False(t, c.Has(c.Key("Go :: Vet")))
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+
+	oldKey, newKey := c.Key("old"), c.Key("new")
+	require.NoError(t, c.Store(oldKey, Entry{TaskID: "old"}))
+	require.NoError(t, c.Store(newKey, Entry{TaskID: "new"}))
+
+	lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(c.entryPath(oldKey), lastWeek, lastWeek))
+
+	removed, err := Prune(dir, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, c.Has(oldKey))
+	assert.True(t, c.Has(newKey))
+
+	removed, err = Prune(dir, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, c.Has(newKey))
+
+	// A cache that was never written to is just empty
+	removed, err = Prune(filepath.Join(dir, "does-not-exist"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+}
+
+func TestHashFiles(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0644))
+
+	before, err := HashFiles(root, []string{"a.go"})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.go"), []byte("package b\n"), 0644))
+	after, err := HashFiles(root, []string{"a.go"})
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+
+	_, err = HashFiles(root, []string{"missing.go"})
+	assert.Error(t, err)
+}
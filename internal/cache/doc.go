@@ -0,0 +1,3 @@
+// Package cache stores the results of Tasks that passed, keyed by a hash of everything that could
+// affect their outcome, so that Tasks whose inputs haven't changed don't need to run again.
+package cache
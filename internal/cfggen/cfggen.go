@@ -0,0 +1,327 @@
+package cfggen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/opensourcecorp/oscar/internal/consts"
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"github.com/opensourcecorp/oscar/internal/oscarcfg"
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"github.com/opensourcecorp/oscar/internal/system"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+	"go.yaml.in/yaml/v4"
+	"golang.org/x/mod/semver"
+)
+
+// defaultVersion is the version written to a new config file if one can't be determined from the
+// repo's Git tags.
+const defaultVersion = "0.1.0"
+
+// Options holds caller-provided settings for [Run].
+type Options struct {
+	// Whether to overwrite an existing config file.
+	Force bool
+}
+
+// composeFile holds the parts of a Compose file that are used to guess container image
+// deliverables.
+type composeFile struct {
+	Services map[string]struct {
+		Build any `yaml:"build"`
+	} `yaml:"services"`
+}
+
+// Run generates an oscar config file from the contents of the repo, and writes it to the default
+// config file path. It refuses to overwrite an existing config file unless [Options.Force] is set,
+// in which case it prints a diff of the changes it made.
+func Run(ctx context.Context, opts Options) error {
+	path := consts.DefaultOscarCfgFileName
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading existing oscar config file: %w", err)
+	}
+	exists := err == nil
+
+	if exists && !opts.Force {
+		return fmt.Errorf("oscar config file '%s' already exists -- pass --force to overwrite it", path)
+	}
+
+	cfg, err := Generate(ctx)
+	if err != nil {
+		return err
+	}
+
+	out, err := oscarcfg.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("internal error rendering generated config: %w", err)
+	}
+
+	if exists {
+		diff, err := diffContents(ctx, existing, out)
+		if err != nil {
+			return err
+		}
+
+		if diff == "" {
+			iprint.Goodf("'%s' is already up to date, nothing to do\n", path)
+			return nil
+		}
+
+		iprint.Infof("Changes to '%s':\n\n%s\n\n", path, diff)
+	}
+
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("writing oscar config file: %w", err)
+	}
+
+	iprint.Goodf("Wrote '%s' -- be sure to review it before committing!\n", path)
+
+	return nil
+}
+
+// Generate returns a [oscarcfgpbv1.Config] populated with guesses based on the contents of the repo.
+func Generate(ctx context.Context) (*oscarcfgpbv1.Config, error) {
+	repo, err := taskutil.NewRepo(ctx, taskutil.RunOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting repo composition: %w", err)
+	}
+
+	cfg := &oscarcfgpbv1.Config{
+		Version: guessVersion(ctx),
+	}
+
+	deliverables := &oscarcfgpbv1.Deliverables{}
+
+	if repo.HasGo {
+		buildSources, err := findGoMainPackages()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(buildSources) > 0 {
+			deliverables.GoGithubRelease = &oscarcfgpbv1.GoGitHubRelease{
+				BuildSources: buildSources,
+			}
+		}
+	}
+
+	if repo.HasContainerfile {
+		image, err := guessContainerImage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		deliverables.ContainerImage = image
+	}
+
+	if deliverables.GetGoGithubRelease() != nil || deliverables.GetContainerImage() != nil {
+		cfg.Deliverables = deliverables
+	}
+
+	iprint.Debugf("generated config: %+v\n", cfg)
+
+	return cfg, nil
+}
+
+// guessVersion returns the version from the repo's latest Git tag if it has one, and a default
+// version otherwise.
+func guessVersion(ctx context.Context) string {
+	latestTag, err := system.RunHostCommand(ctx, []string{"git", "describe", "--tags", "--abbrev=0"})
+	if err != nil {
+		iprint.Debugf("could not find latest Git tag, using default version: %v\n", err)
+		return defaultVersion
+	}
+
+	version := strings.TrimPrefix(latestTag, "v")
+	if !semver.IsValid("v" + version) {
+		iprint.Debugf("latest Git tag '%s' is not a valid version, using default version\n", latestTag)
+		return defaultVersion
+	}
+
+	return version
+}
+
+// findGoMainPackages returns the paths to any "main" packages found directly under the repo's
+// "cmd" directory, e.g. "./cmd/oscar".
+func findGoMainPackages() ([]string, error) {
+	goFiles, err := filepath.Glob(filepath.Join("cmd", "*", "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("finding Go files under cmd/: %w", err)
+	}
+
+	out := make([]string, 0)
+	for _, goFile := range goFiles {
+		if strings.HasSuffix(goFile, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), goFile, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, fmt.Errorf("parsing Go file '%s': %w", goFile, err)
+		}
+
+		pkgPath := "./" + filepath.ToSlash(filepath.Dir(goFile))
+		if f.Name.Name == "main" && !slices.Contains(out, pkgPath) {
+			out = append(out, pkgPath)
+		}
+	}
+
+	slices.Sort(out)
+
+	return out, nil
+}
+
+// guessContainerImage returns a container image deliverable for the repo, named after the repo
+// itself, or after its Compose file's buildable service if it has one. It returns nil if there's
+// nothing at the repo root to build, or if no registry can be guessed.
+func guessContainerImage(ctx context.Context) (*oscarcfgpbv1.ContainerImage, error) {
+	service, err := guessComposeService()
+	if err != nil {
+		return nil, err
+	}
+	if service == "" && !fileExists("Containerfile") && !fileExists("Dockerfile") {
+		iprint.Warnf("found a Containerfile, but none at the repo root or in a docker-compose.yaml, so not adding a container image deliverable\n")
+		return nil, nil
+	}
+
+	remote, err := system.RunHostCommand(ctx, []string{"git", "remote", "get-url", "origin"})
+	if err != nil {
+		iprint.Warnf("could not determine Git remote, so not adding a container image deliverable: %v\n", err)
+		return nil, nil
+	}
+
+	registry, namespace, err := guessRegistry(remote)
+	if err != nil {
+		iprint.Warnf("%v, so not adding a container image deliverable\n", err)
+		return nil, nil
+	}
+
+	image := &oscarcfgpbv1.ContainerImage{
+		Registry:  registry,
+		Namespace: namespace,
+		Name:      guessImageName(remote),
+	}
+	if service != "" {
+		image.Name = service
+		image.Compose = &oscarcfgpbv1.ComposeSource{File: "docker-compose.yaml"}
+	}
+
+	return image, nil
+}
+
+// guessComposeService returns the name of the buildable service in the repo's docker-compose.yaml,
+// or an empty string if it has none.
+func guessComposeService() (string, error) {
+	composeFileContents, err := os.ReadFile("docker-compose.yaml")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading Compose file: %w", err)
+	}
+
+	var compose composeFile
+	if err := yaml.Unmarshal(composeFileContents, &compose); err != nil {
+		return "", fmt.Errorf("parsing Compose file: %w", err)
+	}
+
+	services := make([]string, 0)
+	for name, service := range compose.Services {
+		if service.Build != nil {
+			services = append(services, name)
+		}
+	}
+	slices.Sort(services)
+
+	if len(services) == 0 {
+		return "", nil
+	}
+	if len(services) > 1 {
+		iprint.Warnf("found multiple buildable Compose services %v, using the first one ('%s')\n", services, services[0])
+	}
+
+	return services[0], nil
+}
+
+// guessImageName returns the name of the repo that a Git remote points to, for use as an image
+// name.
+func guessImageName(remote string) string {
+	name := strings.TrimSuffix(strings.TrimRight(remote, "/"), ".git")
+	if i := strings.LastIndexAny(name, "/:"); i != -1 {
+		name = name[i+1:]
+	}
+
+	return strings.ToLower(name)
+}
+
+// fileExists reports whether the provided path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// guessRegistry returns the OCI registry & namespace that a repo's images are likely to live under,
+// based on the repo's Git remote.
+func guessRegistry(remote string) (registry string, namespace string, err error) {
+	groups := regexp.MustCompile(`^(?:https://|ssh://)?(?:[^@/]+@)?([^:/]+)[:/]([^/]+)/`).FindStringSubmatch(remote)
+	if groups == nil {
+		return "", "", fmt.Errorf("could not parse Git remote '%s'", remote)
+	}
+
+	host, owner := groups[1], strings.ToLower(groups[2])
+	switch host {
+	case "github.com":
+		return "ghcr.io", owner, nil
+	case "gitlab.com":
+		return "registry.gitlab.com", owner, nil
+	default:
+		return "", "", fmt.Errorf("no known container registry for Git host '%s'", host)
+	}
+}
+
+// diffContents returns a unified diff between the old & new file contents, or an empty string if
+// they are the same.
+func diffContents(ctx context.Context, oldContents []byte, newContents []byte) (diff string, err error) {
+	tmpDir, err := os.MkdirTemp("", "oscar-init-")
+	if err != nil {
+		return "", fmt.Errorf("creating temp directory: %w", err)
+	}
+	defer func() {
+		if rmErr := os.RemoveAll(tmpDir); rmErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing temp directory: %w", rmErr))
+		}
+	}()
+
+	oldPath := filepath.Join(tmpDir, "old", consts.DefaultOscarCfgFileName)
+	newPath := filepath.Join(tmpDir, "new", consts.DefaultOscarCfgFileName)
+	for path, contents := range map[string][]byte{oldPath: oldContents, newPath: newContents} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", fmt.Errorf("creating temp directory: %w", err)
+		}
+		if err := os.WriteFile(path, contents, 0644); err != nil {
+			return "", fmt.Errorf("writing temp file: %w", err)
+		}
+	}
+
+	// NOTE: 'git diff --no-index' exits 1 when there are differences, so only fail on other codes
+	diff, err = system.RunHostCommand(ctx, []string{"bash", "-c", fmt.Sprintf(`
+		cd '%s'
+		git diff --no-index --no-color -- old/%s new/%s || [[ $? -eq 1 ]]
+		`, tmpDir, consts.DefaultOscarCfgFileName, consts.DefaultOscarCfgFileName,
+	)})
+	if err != nil {
+		return "", fmt.Errorf("diffing config files: %w", err)
+	}
+
+	return diff, nil
+}
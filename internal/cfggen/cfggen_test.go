@@ -0,0 +1,78 @@
+package cfggen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuessRegistry(t *testing.T) {
+	tt := []struct {
+		Name          string
+		Remote        string
+		WantRegistry  string
+		WantNamespace string
+		WantErr       bool
+	}{
+		{
+			Name:          "GitHub SSH",
+			Remote:        "git@github.com:opensourcecorp/oscar.git",
+			WantRegistry:  "ghcr.io",
+			WantNamespace: "opensourcecorp",
+		},
+		{
+			Name:          "GitHub HTTPS with mixed-case owner",
+			Remote:        "https://github.com/OpenSourceCorp/oscar",
+			WantRegistry:  "ghcr.io",
+			WantNamespace: "opensourcecorp",
+		},
+		{
+			Name:          "GitLab SSH URL",
+			Remote:        "ssh://git@gitlab.com/some-group/some-repo.git",
+			WantRegistry:  "registry.gitlab.com",
+			WantNamespace: "some-group",
+		},
+		{
+			Name:    "unknown host",
+			Remote:  "https://git.example.com/team/repo.git",
+			WantErr: true,
+		},
+		{
+			Name:    "unparseable",
+			Remote:  "/some/local/path",
+			WantErr: true,
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			registry, namespace, err := guessRegistry(s.Remote)
+			if s.WantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, s.WantRegistry, registry)
+			assert.Equal(t, s.WantNamespace, namespace)
+		})
+	}
+}
+
+func TestGuessImageName(t *testing.T) {
+	tt := []struct {
+		Remote string
+		Want   string
+	}{
+		{Remote: "git@github.com:opensourcecorp/oscar.git", Want: "oscar"},
+		{Remote: "https://github.com/OpenSourceCorp/Oscar/", Want: "oscar"},
+		{Remote: "ssh://git@gitlab.com/some-group/some-repo.git", Want: "some-repo"},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Remote, func(t *testing.T) {
+			assert.Equal(t, s.Want, guessImageName(s.Remote))
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package cfggen generates oscar config files based on the contents of a repo.
+package cfggen
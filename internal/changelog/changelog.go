@@ -0,0 +1,79 @@
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the changelog file, at the repo root.
+const FileName = "CHANGELOG.md"
+
+var (
+	// versionHeadingRegex matches a changelog's version section headings, e.g. "## [1.0.0] -
+	// 2017-06-20", with a group for the version.
+	versionHeadingRegex = regexp.MustCompile(`^##\s+\[?v?([^\]\s]+)\]?(?:\s+-\s+.*)?\s*$`)
+	// linkDefinitionRegex matches Markdown link reference definitions, which Keep a Changelog uses
+	// at the end of the file for each version's diff link.
+	linkDefinitionRegex = regexp.MustCompile(`^\s*\[[^\]]+\]:\s+\S+`)
+)
+
+// Section returns the contents of the changelog section for the provided version, without its
+// heading. It returns an error if there is no section for the version, or if that section has no
+// entries in it.
+func Section(data []byte, version string) (string, error) {
+	version = strings.TrimPrefix(version, "v")
+
+	var (
+		lines   []string
+		found   bool
+		inFence bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+
+		if !inFence && strings.HasPrefix(line, "## ") {
+			if found {
+				// Reached the next version's section
+				break
+			}
+			groups := versionHeadingRegex.FindStringSubmatch(line)
+			found = groups != nil && groups[1] == version
+			continue
+		}
+
+		if found && (inFence || !linkDefinitionRegex.MatchString(line)) {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading %s: %w", FileName, err)
+	}
+
+	if !found {
+		return "", fmt.Errorf("%s has no section for version %s", FileName, version)
+	}
+
+	hasEntries := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		// Category headings like "### Added" don't count as entries on their own
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			hasEntries = true
+			break
+		}
+	}
+	if !hasEntries {
+		return "", fmt.Errorf("%s section for version %s is empty", FileName, version)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
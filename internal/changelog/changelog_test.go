@@ -0,0 +1,81 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChangelog = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+### Added
+
+- Something in progress
+
+## [1.1.0] - 2025-09-01
+
+### Added
+
+- Version bumps
+
+` + "```sh\n## not a heading\n```" + `
+
+## v1.0.1
+
+### Fixed
+
+## [1.0.0] - 2025-08-01
+
+- First release
+
+[unreleased]: https://github.com/opensourcecorp/oscar/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/opensourcecorp/oscar/compare/v1.0.0...v1.1.0
+`
+
+func TestSection(t *testing.T) {
+	tt := []struct {
+		Name    string
+		Version string
+		Want    string
+		WantErr bool
+	}{
+		{
+			Name:    "bracketed with date",
+			Version: "1.1.0",
+			Want:    "### Added\n\n- Version bumps\n\n```sh\n## not a heading\n```",
+		},
+		{
+			Name:    "last section, without link definitions",
+			Version: "1.0.0",
+			Want:    "- First release",
+		},
+		{
+			Name:    "only category headings",
+			Version: "1.0.1",
+			WantErr: true,
+		},
+		{
+			Name:    "missing",
+			Version: "2.0.0",
+			WantErr: true,
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, err := Section([]byte(testChangelog), s.Version)
+			if s.WantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+}
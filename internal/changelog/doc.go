@@ -0,0 +1,3 @@
+// Package changelog reads changelog files in the "Keep a Changelog" format. See
+// https://keepachangelog.com.
+package changelog
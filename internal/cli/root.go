@@ -5,12 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
+	"slices"
 
+	"github.com/opensourcecorp/oscar"
+	"github.com/opensourcecorp/oscar/internal/cache"
+	"github.com/opensourcecorp/oscar/internal/cfggen"
 	"github.com/opensourcecorp/oscar/internal/consts"
+	"github.com/opensourcecorp/oscar/internal/hooks"
 	"github.com/opensourcecorp/oscar/internal/oscarcfg"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"github.com/opensourcecorp/oscar/internal/report"
 	"github.com/opensourcecorp/oscar/internal/tasks/ci"
 	"github.com/opensourcecorp/oscar/internal/tasks/delivery"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+	"github.com/opensourcecorp/oscar/internal/versionbump"
 	"github.com/urfave/cli/v3"
 )
 
@@ -21,9 +30,38 @@ const (
 	noBannerFlagName = "no-banner"
 	noColorFlagName  = "no-color"
 
-	ciCommandName = "ci"
+	ciCommandName   = "ci"
+	jobsFlagName    = "jobs"
+	reportFlagName  = "report"
+	onlyFlagName    = "only"
+	skipFlagName    = "skip"
+	noCacheFlagName = "no-cache"
+
+	diffMaxLinesFlagName = "diff-max-lines"
+	patchFileFlagName    = "patch-file"
+
+	changedSinceFlagName = "changed-since"
+	verboseFlagName      = "verbose"
+	stagedFlagName       = "staged"
+
+	fixCommandName = "fix"
 
 	deliverCommandName = "deliver"
+
+	initCommandName = "init"
+	forceFlagName   = "force"
+
+	versionCommandName     = "version"
+	versionBumpCommandName = "bump"
+	fromCommitsFlagName    = "from-commits"
+
+	cacheCommandName      = "cache"
+	cachePruneCommandName = "prune"
+	olderThanFlagName     = "older-than"
+
+	hooksCommandName          = "hooks"
+	hooksInstallCommandName   = "install"
+	hooksUninstallCommandName = "uninstall"
 )
 
 // NewRootCmd defines & returns the CLI command used as oscar's entrypoint.
@@ -70,11 +108,81 @@ func NewRootCmd() *cli.Command {
 				Name:   ciCommandName,
 				Usage:  "Runs CI tasks",
 				Action: ciAction,
+				Flags:  slices.Concat(runFlags(), ciTaskFlags(""), changedFilesFlags()),
+			},
+			{
+				Name:   fixCommandName,
+				Usage:  "Runs only the CI tasks that fix problems by rewriting files (like formatters), and keeps their changes",
+				Action: fixAction,
+				Flags:  slices.Concat(runFlags(), ciTaskFlags(""), changedFilesFlags()),
 			},
 			{
 				Name:   deliverCommandName,
 				Usage:  "Runs Delivery tasks",
 				Action: deliverAction,
+				Flags:  slices.Concat(runFlags(), ciTaskFlags(" Only affects the CI tasks that run before the Delivery tasks.")),
+			},
+			{
+				Name:   initCommandName,
+				Usage:  "Generates an oscar config file based on the contents of the repo",
+				Action: initAction,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  forceFlagName,
+						Usage: "Overwrite an existing oscar config file, printing a diff of the changes made to it.",
+					},
+				},
+			},
+			{
+				Name:  versionCommandName,
+				Usage: "Manages the version in the oscar config file",
+				Commands: []*cli.Command{
+					{
+						Name:      versionBumpCommandName,
+						Usage:     "Increments the version in the oscar config file, relative to the version on the base branch",
+						ArgsUsage: "major|minor|patch|prerelease",
+						Action:    versionBumpAction,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  fromCommitsFlagName,
+								Usage: "Derive the bump level from the Conventional Commit messages since the latest tag, instead of passing it as an argument.",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  cacheCommandName,
+				Usage: "Manages oscar's cache of passed task results",
+				Commands: []*cli.Command{
+					{
+						Name:   cachePruneCommandName,
+						Usage:  "Removes cached task results",
+						Action: cachePruneAction,
+						Flags: []cli.Flag{
+							&cli.DurationFlag{
+								Name:  olderThanFlagName,
+								Usage: "Only remove results that have not been used in at least this long (e.g. '168h'). By default, every result is removed.",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  hooksCommandName,
+				Usage: "Manages Git hooks that run oscar before commits & pushes",
+				Commands: []*cli.Command{
+					{
+						Name:   hooksInstallCommandName,
+						Usage:  "Installs pre-commit & pre-push hooks into the current Git repo. Any existing hooks are kept, and run first.",
+						Action: hooksInstallAction,
+					},
+					{
+						Name:   hooksUninstallCommandName,
+						Usage:  "Removes oscar's hooks from the current Git repo, and restores any hooks they replaced",
+						Action: hooksUninstallAction,
+					},
+				},
 			},
 		},
 	}
@@ -82,11 +190,128 @@ func NewRootCmd() *cli.Command {
 	return cmd
 }
 
-// getVersion retrieves the version of the codebase.
+// runFlags returns the flags shared by the subcommands that run Tasks.
+func runFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  reportFlagName,
+			Usage: "Write a machine-readable report of the run, as FORMAT=PATH. Supported formats are 'json', 'junit', and 'sarif'. May be passed multiple times.",
+		},
+		&cli.StringSliceFlag{
+			Name:  onlyFlagName,
+			Usage: "Only run the matching tasks, given as either a language/tooling group (e.g. 'Go') or a single task in a group (e.g. 'Go::Build'). May be passed multiple times.",
+		},
+		&cli.StringSliceFlag{
+			Name:  skipFlagName,
+			Usage: "Skip the matching tasks, in the same format as --only. Takes precedence over --only. May be passed multiple times.",
+		},
+		&cli.BoolFlag{
+			Name:    verboseFlagName,
+			Aliases: []string{"v"},
+			Usage:   "Print the output of every task as it runs, instead of only the output of tasks that fail.",
+		},
+	}
+}
+
+// ciTaskFlags returns the flags that only affect how CI Tasks run. Since the deliver subcommand runs
+// CI Tasks first, it takes these too, with usageSuffix noting that they don't affect its own Tasks.
+func ciTaskFlags(usageSuffix string) []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:    jobsFlagName,
+			Aliases: []string{"j"},
+			Usage:   "The maximum number of CI tasks to run at the same time. Tasks that depend on each other always run in order." + usageSuffix,
+			Value:   runtime.NumCPU(),
+		},
+		&cli.BoolFlag{
+			Name:  noCacheFlagName,
+			Usage: "Run every CI task, even ones that already passed against the exact same files in an earlier run." + usageSuffix,
+		},
+		&cli.IntFlag{
+			Name:  diffMaxLinesFlagName,
+			Usage: "The maximum number of lines to print of the diff of any files that a CI task changed. 0 means no limit." + usageSuffix,
+		},
+		&cli.StringFlag{
+			Name:  patchFileFlagName,
+			Usage: "Write a patch of every change that CI tasks made to files to this path, which can be applied with 'git apply' to make the same changes." + usageSuffix,
+		},
+	}
+}
+
+// changedFilesFlags returns the flags that limit a run to only some changed files.
+func changedFilesFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  changedSinceFlagName,
+			Usage: "Only check files that have changed since this Git ref (e.g. 'origin/main'), including uncommitted files. Language groups with no changed files are skipped entirely.",
+		},
+		&cli.BoolFlag{
+			Name:  stagedFlagName,
+			Usage: "Only check files that are staged in the Git index, e.g. from a pre-commit hook. Unstaged changes are set aside during the run, so exactly what's staged gets checked. Can't be used with --" + changedSinceFlagName + ".",
+		},
+	}
+}
+
+// runOptionsFromFlags builds a [taskutil.RunOptions] from the flags in [runFlags] &
+// [ciTaskFlags], along with any requested report specs.
+func runOptionsFromFlags(cmd *cli.Command) (taskutil.RunOptions, []report.Spec, error) {
+	opts := taskutil.RunOptions{
+		Jobs: cmd.Int(jobsFlagName),
+		Only: cmd.StringSlice(onlyFlagName),
+		Skip: cmd.StringSlice(skipFlagName),
+		// NOTE: only defined for some subcommands, but reads as empty otherwise
+		ChangedSince: cmd.String(changedSinceFlagName),
+		Staged:       cmd.Bool(stagedFlagName),
+		Verbose:      cmd.Bool(verboseFlagName),
+		NoCache:      cmd.Bool(noCacheFlagName),
+		DiffMaxLines: cmd.Int(diffMaxLinesFlagName),
+		PatchFile:    cmd.String(patchFileFlagName),
+	}
+
+	for _, filter := range append(slices.Clone(opts.Only), opts.Skip...) {
+		if err := taskutil.ValidateTaskFilter(filter); err != nil {
+			return taskutil.RunOptions{}, nil, err
+		}
+	}
+
+	specs := make([]report.Spec, 0)
+	for _, s := range cmd.StringSlice(reportFlagName) {
+		spec, err := report.ParseSpec(s)
+		if err != nil {
+			return taskutil.RunOptions{}, nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	if len(specs) > 0 {
+		opts.Recorder = &taskutil.Recorder{}
+	}
+
+	return opts, specs, nil
+}
+
+// writeReports writes every requested report for the runs recorded in opts.
+func writeReports(opts taskutil.RunOptions, specs []report.Spec) error {
+	var errs error
+	for _, spec := range specs {
+		errs = errors.Join(errs, report.Write(spec, opts.Recorder.Records()))
+	}
+
+	return errs
+}
+
+// getVersion retrieves oscar's own version, from its embedded config file. Note that this must not
+// read the config file of the repo being run against, since that file may not exist yet (e.g. when
+// running the init subcommand).
 func getVersion() (string, error) {
-	cfg, err := oscarcfg.Get()
+	cfgData, err := oscar.Files.ReadFile(consts.DefaultOscarCfgFileName)
 	if err != nil {
-		return "", fmt.Errorf("reading oscar config file: %w", err)
+		return "", fmt.Errorf("reading embedded oscar config file: %w", err)
+	}
+
+	cfg, err := oscarcfg.Parse(cfgData)
+	if err != nil {
+		return "", fmt.Errorf("parsing embedded oscar config file: %w", err)
 	}
 
 	return cfg.Version, nil
@@ -100,24 +325,123 @@ func rootAction(_ context.Context, cmd *cli.Command) error {
 }
 
 // ciAction defines the logic for oscar's ci subcommand.
-func ciAction(ctx context.Context, _ *cli.Command) error {
+func ciAction(ctx context.Context, cmd *cli.Command) error {
 	iprint.Banner()
 	iprint.Debugf("oscar ci subcommand\n")
 
-	if err := ci.Run(ctx); err != nil {
-		return fmt.Errorf("running CI tasks: %w", err)
+	opts, specs, err := runOptionsFromFlags(cmd)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	runErr := ci.Run(ctx, opts)
+	if runErr != nil {
+		runErr = fmt.Errorf("running CI tasks: %w", runErr)
+	}
+
+	return errors.Join(runErr, writeReports(opts, specs))
+}
+
+// fixAction defines the logic for oscar's fix subcommand.
+func fixAction(ctx context.Context, cmd *cli.Command) error {
+	iprint.Banner()
+	iprint.Debugf("oscar fix subcommand\n")
+
+	opts, specs, err := runOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	opts.Fix = true
+
+	runErr := ci.Run(ctx, opts)
+	if runErr != nil {
+		runErr = fmt.Errorf("running fix tasks: %w", runErr)
+	}
+
+	return errors.Join(runErr, writeReports(opts, specs))
 }
 
 // deliverAction defines the logic for oscar's deliver subcommand.
-func deliverAction(ctx context.Context, _ *cli.Command) error {
+func deliverAction(ctx context.Context, cmd *cli.Command) error {
 	iprint.Banner()
 	iprint.Debugf("oscar deliver subcommand\n")
 
-	if err := delivery.Run(ctx); err != nil {
-		return fmt.Errorf("running Delivery tasks: %w", err)
+	opts, specs, err := runOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	runErr := delivery.Run(ctx, opts)
+	if runErr != nil {
+		runErr = fmt.Errorf("running Delivery tasks: %w", runErr)
+	}
+
+	return errors.Join(runErr, writeReports(opts, specs))
+}
+
+// initAction defines the logic for oscar's init subcommand.
+func initAction(ctx context.Context, cmd *cli.Command) error {
+	iprint.Banner()
+	iprint.Debugf("oscar init subcommand\n")
+
+	if err := cfggen.Run(ctx, cfggen.Options{Force: cmd.Bool(forceFlagName)}); err != nil {
+		return fmt.Errorf("generating oscar config file: %w", err)
+	}
+
+	return nil
+}
+
+// versionBumpAction defines the logic for oscar's version bump subcommand.
+func versionBumpAction(ctx context.Context, cmd *cli.Command) error {
+	iprint.Debugf("oscar version bump subcommand\n")
+
+	fromCommits := cmd.Bool(fromCommitsFlagName)
+	if cmd.Args().Len() > 1 || (cmd.Args().Len() == 0 && !fromCommits) {
+		return errors.New("expected exactly one bump level (major, minor, patch, or prerelease), or --from-commits")
+	}
+
+	opts := versionbump.Options{
+		Level:       cmd.Args().First(),
+		FromCommits: fromCommits,
+	}
+	if err := versionbump.Run(ctx, opts); err != nil {
+		return fmt.Errorf("bumping version: %w", err)
+	}
+
+	return nil
+}
+
+// cachePruneAction defines the logic for oscar's cache prune subcommand.
+func cachePruneAction(_ context.Context, cmd *cli.Command) error {
+	iprint.Debugf("oscar cache prune subcommand\n")
+
+	removed, err := cache.Prune(consts.OscarTaskCacheDir, cmd.Duration(olderThanFlagName))
+	if err != nil {
+		return err
+	}
+
+	iprint.Infof("Removed %d cached task result(s)\n", removed)
+
+	return nil
+}
+
+// hooksInstallAction defines the logic for oscar's hooks install subcommand.
+func hooksInstallAction(ctx context.Context, _ *cli.Command) error {
+	iprint.Debugf("oscar hooks install subcommand\n")
+
+	if err := hooks.Install(ctx); err != nil {
+		return fmt.Errorf("installing Git hooks: %w", err)
+	}
+
+	return nil
+}
+
+// hooksUninstallAction defines the logic for oscar's hooks uninstall subcommand.
+func hooksUninstallAction(ctx context.Context, _ *cli.Command) error {
+	iprint.Debugf("oscar hooks uninstall subcommand\n")
+
+	if err := hooks.Uninstall(ctx); err != nil {
+		return fmt.Errorf("uninstalling Git hooks: %w", err)
 	}
 
 	return nil
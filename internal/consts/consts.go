@@ -30,6 +30,10 @@ var (
 	OscarHome = filepath.Join(os.Getenv("HOME"), ".oscar")
 	// OscarHomeBin is the directory where any commands that oscar installs for itself will live.
 	OscarHomeBin = filepath.Join(OscarHome, "bin")
+	// OscarTaskCacheDir is the directory where results of passed Tasks are cached.
+	OscarTaskCacheDir = filepath.Join(OscarHome, "cache", "tasks")
+	// OscarRunLogDir is the directory where each run's per-Task log files are written.
+	OscarRunLogDir = filepath.Join(OscarHome, "logs", "runs")
 
 	// MiseBinPath is the absolute path to the mise binary, if oscar is the one installing it.
 	MiseBinPath = filepath.Join(OscarHomeBin, "mise")
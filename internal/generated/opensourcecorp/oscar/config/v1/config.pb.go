@@ -31,7 +31,28 @@ type Config struct {
 	// Example: "1.0.0"
 	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
 	// Deliverables is the collection of possible deliverable artifacts.
-	Deliverables  *Deliverables `protobuf:"bytes,2,opt,name=deliverables,proto3" json:"deliverables,omitempty"`
+	Deliverables *Deliverables `protobuf:"bytes,2,opt,name=deliverables,proto3" json:"deliverables,omitempty"`
+	// Projects is an optional list of directories (relative to the repo root) to treat as separate
+	// project roots, in addition to any that oscar discovers on its own via e.g. nested `go.mod` or
+	// `pyproject.toml` files. CI tasks for each project run from within its root directory.
+	//
+	// Example: - "infra/prod"
+	Projects []string `protobuf:"bytes,3,rep,name=projects,proto3" json:"projects,omitempty"`
+	// BaseBranch is the name of the branch that changes are merged into, which e.g. the version is
+	// checked against during CI. Defaults to "main" if not set.
+	//
+	// Example: "develop"
+	BaseBranch string `protobuf:"bytes,4,opt,name=base_branch,json=baseBranch,proto3" json:"base_branch,omitempty"`
+	// CustomTasks is an optional list of checks to run during CI alongside oscar's own, for anything
+	// that oscar doesn't cover itself. oscar's own checks can't be changed or replaced by these.
+	CustomTasks []*CustomTask `protobuf:"bytes,5,rep,name=custom_tasks,json=customTasks,proto3" json:"custom_tasks,omitempty"`
+	// TaskTimeouts optionally overrides how long tasks may run before they're canceled, keyed by a task
+	// filter like those for `--only` & `--skip` (a group, or a group & task name separated by "::").
+	// Values are durations like "90s" or "1h", or "0" for no timeout. If both a group & one of its
+	// tasks are listed, the task's own entry is used.
+	//
+	// Example: "Go::Tests": "45m"
+	TaskTimeouts  map[string]string `protobuf:"bytes,6,rep,name=task_timeouts,json=taskTimeouts,proto3" json:"task_timeouts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -80,6 +101,145 @@ func (x *Config) GetDeliverables() *Deliverables {
 	return nil
 }
 
+func (x *Config) GetProjects() []string {
+	if x != nil {
+		return x.Projects
+	}
+	return nil
+}
+
+func (x *Config) GetBaseBranch() string {
+	if x != nil {
+		return x.BaseBranch
+	}
+	return ""
+}
+
+func (x *Config) GetCustomTasks() []*CustomTask {
+	if x != nil {
+		return x.CustomTasks
+	}
+	return nil
+}
+
+func (x *Config) GetTaskTimeouts() map[string]string {
+	if x != nil {
+		return x.TaskTimeouts
+	}
+	return nil
+}
+
+// CustomTask defines a check that oscar runs during CI alongside its own.
+type CustomTask struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the task, as shown in oscar's output. Must be unique within its group, and can't be
+	// the same as one of oscar's own tasks in that group.
+	//
+	// Example: "Lint (sqlfluff)"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The language/tooling group to list the task under, which may be one of oscar's own (like "Go").
+	// Defaults to "Custom" if not set.
+	//
+	// Example: "SQL"
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// The command & its arguments to run. It's run through mise like oscar's own tools, so it can use
+	// any tool that mise provides, as well as anything on the PATH.
+	//
+	// Example: - "sqlfluff"
+	//          - "lint"
+	Command []string `protobuf:"bytes,3,rep,name=command,proto3" json:"command,omitempty"`
+	// Optional gitignore-style glob patterns for the files that the task checks. If set, the task is
+	// run in every project that has matching files, with those files' paths (relative to the
+	// project's root) added to the end of the command. If not set, the task is run once, at the repo
+	// root.
+	//
+	// Example: - "*.sql"
+	Files []string `protobuf:"bytes,4,rep,name=files,proto3" json:"files,omitempty"`
+	// Optional environment variables to set for the command.
+	//
+	// Example: SQLFLUFF_DIALECT: "postgres"
+	Env map[string]string `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Whether the command fixes what it finds by rewriting files (like a formatter), so that `oscar
+	// fix` runs it too. Just like for oscar's own tasks, any changes it makes still fail it during CI.
+	//
+	// Example: true
+	Mutating      bool `protobuf:"varint,6,opt,name=mutating,proto3" json:"mutating,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomTask) Reset() {
+	*x = CustomTask{}
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomTask) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomTask) ProtoMessage() {}
+
+func (x *CustomTask) ProtoReflect() protoreflect.Message {
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomTask.ProtoReflect.Descriptor instead.
+func (*CustomTask) Descriptor() ([]byte, []int) {
+	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CustomTask) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CustomTask) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *CustomTask) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *CustomTask) GetFiles() []string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *CustomTask) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *CustomTask) GetMutating() bool {
+	if x != nil {
+		return x.Mutating
+	}
+	return false
+}
+
 // Deliverables contains a field for each possible deliverable.
 type Deliverables struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -93,7 +253,7 @@ type Deliverables struct {
 
 func (x *Deliverables) Reset() {
 	*x = Deliverables{}
-	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[1]
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -105,7 +265,7 @@ func (x *Deliverables) String() string {
 func (*Deliverables) ProtoMessage() {}
 
 func (x *Deliverables) ProtoReflect() protoreflect.Message {
-	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[1]
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -118,7 +278,7 @@ func (x *Deliverables) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Deliverables.ProtoReflect.Descriptor instead.
 func (*Deliverables) Descriptor() ([]byte, []int) {
-	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{1}
+	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Deliverables) GetGoGithubRelease() *GoGitHubRelease {
@@ -153,7 +313,7 @@ type GoGitHubRelease struct {
 
 func (x *GoGitHubRelease) Reset() {
 	*x = GoGitHubRelease{}
-	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[2]
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -165,7 +325,7 @@ func (x *GoGitHubRelease) String() string {
 func (*GoGitHubRelease) ProtoMessage() {}
 
 func (x *GoGitHubRelease) ProtoReflect() protoreflect.Message {
-	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[2]
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -178,7 +338,7 @@ func (x *GoGitHubRelease) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GoGitHubRelease.ProtoReflect.Descriptor instead.
 func (*GoGitHubRelease) Descriptor() ([]byte, []int) {
-	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{2}
+	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *GoGitHubRelease) GetBuildSources() []string {
@@ -210,14 +370,60 @@ type ContainerImage struct {
 	// necessary based on the registry, e.g. "my-repo/my-image-group/my-image".
 	//
 	// Example: "oscar"
-	Name          string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Optionally sets how to log in to the registry before pushing to it. If not set, this is based
+	// on the registry: "ghcr" for "ghcr.io", "dockerhub" for "docker.io", and "docker-config" for
+	// anything else.
+	Auth *RegistryAuth `protobuf:"bytes,4,opt,name=auth,proto3" json:"auth,omitempty"`
+	// Optionally, the path to the Containerfile to build, relative to the repo root. Defaults to the
+	// "Containerfile" in the build context, or to its "Dockerfile" if there's no "Containerfile".
+	//
+	// Example: "build/Containerfile"
+	Containerfile string `protobuf:"bytes,5,opt,name=containerfile,proto3" json:"containerfile,omitempty"`
+	// Optionally, the build context directory, relative to the repo root. Defaults to the repo root.
+	//
+	// Example: "services/api"
+	Context string `protobuf:"bytes,6,opt,name=context,proto3" json:"context,omitempty"`
+	// Optional build args to pass to the build.
+	//
+	// Example: {"GO_VERSION": "1.25.0"}
+	BuildArgs map[string]string `protobuf:"bytes,7,rep,name=build_args,json=buildArgs,proto3" json:"build_args,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Optionally, the build stage to build, for multi-stage Containerfiles. Defaults to the last
+	// stage.
+	//
+	// Example: "release"
+	Target string `protobuf:"bytes,8,opt,name=target,proto3" json:"target,omitempty"`
+	// Optionally, the tool to build & push the image with, which is one of "docker" (via `docker
+	// buildx`), "podman", or "buildah". Defaults to "docker".
+	//
+	// Example: "podman"
+	Builder string `protobuf:"bytes,9,opt,name=builder,proto3" json:"builder,omitempty"`
+	// Optionally, a Compose file service to read the build settings from, instead of setting them
+	// above. Any of the above that are also set take precedence over the Compose file's.
+	Compose *ComposeSource `protobuf:"bytes,10,opt,name=compose,proto3" json:"compose,omitempty"`
+	// Optionally, the platforms to build the image for, as "os/arch" or "os/arch/variant". If set,
+	// the image is pushed as a multi-platform index, with platforms other than the host's built under
+	// QEMU emulation. Defaults to just the host's platform.
+	//
+	// Example: ["linux/amd64", "linux/arm64"]
+	Platforms []string `protobuf:"bytes,11,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	// Optionally sets which tags the image is pushed with. Defaults to the version (e.g. "1.2.3") on
+	// the release branch, and to "<branch>-<commit>" on any other.
+	Tags *TagPolicy `protobuf:"bytes,12,opt,name=tags,proto3" json:"tags,omitempty"`
+	// Optionally, the image to install QEMU emulators from with the "docker" builder, for any of the
+	// platforms above that it can't build for yet. It must be pinned by digest, since it runs as a
+	// privileged container. If not set, no emulators are installed, and oscar warns about any
+	// platforms that would need them.
+	//
+	// Example: "docker.io/tonistiigi/binfmt@sha256:<digest>"
+	EmulatorImage string `protobuf:"bytes,13,opt,name=emulator_image,json=emulatorImage,proto3" json:"emulator_image,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ContainerImage) Reset() {
 	*x = ContainerImage{}
-	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[3]
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -229,7 +435,7 @@ func (x *ContainerImage) String() string {
 func (*ContainerImage) ProtoMessage() {}
 
 func (x *ContainerImage) ProtoReflect() protoreflect.Message {
-	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[3]
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -242,7 +448,7 @@ func (x *ContainerImage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ContainerImage.ProtoReflect.Descriptor instead.
 func (*ContainerImage) Descriptor() ([]byte, []int) {
-	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{3}
+	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ContainerImage) GetRegistry() string {
@@ -266,24 +472,359 @@ func (x *ContainerImage) GetName() string {
 	return ""
 }
 
+func (x *ContainerImage) GetAuth() *RegistryAuth {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+func (x *ContainerImage) GetContainerfile() string {
+	if x != nil {
+		return x.Containerfile
+	}
+	return ""
+}
+
+func (x *ContainerImage) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *ContainerImage) GetBuildArgs() map[string]string {
+	if x != nil {
+		return x.BuildArgs
+	}
+	return nil
+}
+
+func (x *ContainerImage) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ContainerImage) GetBuilder() string {
+	if x != nil {
+		return x.Builder
+	}
+	return ""
+}
+
+func (x *ContainerImage) GetCompose() *ComposeSource {
+	if x != nil {
+		return x.Compose
+	}
+	return nil
+}
+
+func (x *ContainerImage) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+func (x *ContainerImage) GetTags() *TagPolicy {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ContainerImage) GetEmulatorImage() string {
+	if x != nil {
+		return x.EmulatorImage
+	}
+	return ""
+}
+
+// TagPolicy sets which tags a container image is pushed with, each of which is one of:
+//
+//   - "version": the full version, e.g. "1.2.3" or "1.2.3-rc1", with any build metadata's "+" replaced
+//     by "-" (e.g. "1.2.3-build5"), since image tags can't contain it
+//   - "major_minor": the major & minor version, e.g. "1.2", for stable (non-prerelease) versions only
+//   - "latest": "latest", for stable versions only
+//   - "commit": the short commit SHA, e.g. "a1b2c3d"
+//   - "branch": the branch name, e.g. "my-feature"
+//   - "branch_commit": the branch name & short commit SHA, e.g. "my-feature-a1b2c3d"
+//
+// Tags taken from Git ("commit", "branch", and "branch_commit") get a "-dirty" suffix if the repo has
+// uncommitted changes, since they'd otherwise claim to be exactly that commit. Version tags don't,
+// since they name a release rather than a commit.
+type TagPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optionally, the branch that releases are pushed from. Defaults to the config's `base_branch`.
+	//
+	// Example: "release"
+	ReleaseBranch string `protobuf:"bytes,1,opt,name=release_branch,json=releaseBranch,proto3" json:"release_branch,omitempty"`
+	// Optionally, the tags to push on the release branch. Defaults to ["version"].
+	//
+	// Example: ["version", "major_minor", "latest"]
+	Release []string `protobuf:"bytes,2,rep,name=release,proto3" json:"release,omitempty"`
+	// Optionally, the tags to push on any other branch. Defaults to ["branch_commit"].
+	//
+	// Example: ["branch", "commit"]
+	Other         []string `protobuf:"bytes,3,rep,name=other,proto3" json:"other,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagPolicy) Reset() {
+	*x = TagPolicy{}
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagPolicy) ProtoMessage() {}
+
+func (x *TagPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagPolicy.ProtoReflect.Descriptor instead.
+func (*TagPolicy) Descriptor() ([]byte, []int) {
+	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TagPolicy) GetReleaseBranch() string {
+	if x != nil {
+		return x.ReleaseBranch
+	}
+	return ""
+}
+
+func (x *TagPolicy) GetRelease() []string {
+	if x != nil {
+		return x.Release
+	}
+	return nil
+}
+
+func (x *TagPolicy) GetOther() []string {
+	if x != nil {
+		return x.Other
+	}
+	return nil
+}
+
+// ComposeSource points to a service in a Compose file whose `build` section describes an image.
+type ComposeSource struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path to the Compose file, relative to the repo root.
+	//
+	// Example: "docker-compose.yaml"
+	File string `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	// Optionally, the name of the service to read. Defaults to the image's `name`.
+	//
+	// Example: "api"
+	Service       string `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComposeSource) Reset() {
+	*x = ComposeSource{}
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComposeSource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComposeSource) ProtoMessage() {}
+
+func (x *ComposeSource) ProtoReflect() protoreflect.Message {
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComposeSource.ProtoReflect.Descriptor instead.
+func (*ComposeSource) Descriptor() ([]byte, []int) {
+	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ComposeSource) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *ComposeSource) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+// RegistryAuth defines how to log in to a container registry.
+type RegistryAuth struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The provider to log in with, which is one of:
+	//
+	// - "ghcr": GitHub Container Registry, with a token from the `GITHUB_TOKEN` env var
+	// - "dockerhub": Docker Hub, with the `DOCKERHUB_USERNAME` & `DOCKERHUB_TOKEN` env vars
+	// - "env": any registry, with a username & password from the env vars named below
+	// - "docker-config": no login, and instead reuse the credentials for the registry that are
+	//   already in the Docker config file (e.g. from running `docker login` yourself)
+	//
+	// Example: "env"
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// For the "env" provider, the name of the env var that holds the username. Defaults to
+	// "OSCAR_REGISTRY_USERNAME" if not set.
+	//
+	// Example: "QUAY_USERNAME"
+	UsernameEnv string `protobuf:"bytes,2,opt,name=username_env,json=usernameEnv,proto3" json:"username_env,omitempty"`
+	// For the "env" provider, the name of the env var that holds the password or token. Defaults to
+	// "OSCAR_REGISTRY_PASSWORD" if not set.
+	//
+	// Example: "QUAY_TOKEN"
+	PasswordEnv   string `protobuf:"bytes,3,opt,name=password_env,json=passwordEnv,proto3" json:"password_env,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryAuth) Reset() {
+	*x = RegistryAuth{}
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryAuth) ProtoMessage() {}
+
+func (x *RegistryAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_opensourcecorp_oscar_config_v1_config_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryAuth.ProtoReflect.Descriptor instead.
+func (*RegistryAuth) Descriptor() ([]byte, []int) {
+	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RegistryAuth) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *RegistryAuth) GetUsernameEnv() string {
+	if x != nil {
+		return x.UsernameEnv
+	}
+	return ""
+}
+
+func (x *RegistryAuth) GetPasswordEnv() string {
+	if x != nil {
+		return x.PasswordEnv
+	}
+	return ""
+}
+
 var File_opensourcecorp_oscar_config_v1_config_proto protoreflect.FileDescriptor
 
 const file_opensourcecorp_oscar_config_v1_config_proto_rawDesc = "" +
 	"\n" +
-	"+opensourcecorp/oscar/config/v1/config.proto\x12\x1eopensourcecorp.oscar.config.v1\x1a\x1bbuf/validate/validate.proto\"\xb6\x01\n" +
+	"+opensourcecorp/oscar/config/v1/config.proto\x12\x1eopensourcecorp.oscar.config.v1\x1a\x1bbuf/validate/validate.proto\"\x8d\x04\n" +
 	"\x06Config\x12Z\n" +
 	"\aversion\x18\x01 \x01(\tB@\xbaH=r;29^[0-9]+\\.[0-9]+\\.[0-9]+(-[a-zA-Z0-9]+)?(\\+[a-zA-Z0-9]+)?$R\aversion\x12P\n" +
-	"\fdeliverables\x18\x02 \x01(\v2,.opensourcecorp.oscar.config.v1.DeliverablesR\fdeliverables\"\xc4\x01\n" +
+	"\fdeliverables\x18\x02 \x01(\v2,.opensourcecorp.oscar.config.v1.DeliverablesR\fdeliverables\x12*\n" +
+	"\bprojects\x18\x03 \x03(\tB\x0e\xbaH\v\x92\x01\b\x18\x01\"\x04r\x02\x10\x01R\bprojects\x12:\n" +
+	"\vbase_branch\x18\x04 \x01(\tB\x19\xbaH\x16r\x142\x12^[A-Za-z0-9._/-]*$R\n" +
+	"baseBranch\x12M\n" +
+	"\fcustom_tasks\x18\x05 \x03(\v2*.opensourcecorp.oscar.config.v1.CustomTaskR\vcustomTasks\x12]\n" +
+	"\rtask_timeouts\x18\x06 \x03(\v28.opensourcecorp.oscar.config.v1.Config.TaskTimeoutsEntryR\ftaskTimeouts\x1a?\n" +
+	"\x11TaskTimeoutsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc6\x02\n" +
+	"\n" +
+	"CustomTask\x12\x1e\n" +
+	"\x04name\x18\x01 \x01(\tB\n" +
+	"\xbaH\a\xc8\x01\x01r\x02\x18 R\x04name\x12\x1d\n" +
+	"\x05group\x18\x02 \x01(\tB\a\xbaH\x04r\x02\x18 R\x05group\x12\"\n" +
+	"\acommand\x18\x03 \x03(\tB\b\xbaH\x05\x92\x01\x02\b\x01R\acommand\x12\x14\n" +
+	"\x05files\x18\x04 \x03(\tR\x05files\x12k\n" +
+	"\x03env\x18\x05 \x03(\v23.opensourcecorp.oscar.config.v1.CustomTask.EnvEntryB$\xbaH!\x9a\x01\x1e\"\x1cr\x1a2\x18^[A-Za-z_][A-Za-z0-9_]*$R\x03env\x12\x1a\n" +
+	"\bmutating\x18\x06 \x01(\bR\bmutating\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc4\x01\n" +
 	"\fDeliverables\x12[\n" +
 	"\x11go_github_release\x18\x01 \x01(\v2/.opensourcecorp.oscar.config.v1.GoGitHubReleaseR\x0fgoGithubRelease\x12W\n" +
 	"\x0fcontainer_image\x18\x02 \x01(\v2..opensourcecorp.oscar.config.v1.ContainerImageR\x0econtainerImage\"T\n" +
 	"\x0fGoGitHubRelease\x12+\n" +
 	"\rbuild_sources\x18\x01 \x03(\tB\x06\xbaH\x03\xc8\x01\x01R\fbuildSources\x12\x14\n" +
-	"\x05draft\x18\x02 \x01(\bR\x05draft\"v\n" +
+	"\x05draft\x18\x02 \x01(\bR\x05draft\"\x92\x06\n" +
 	"\x0eContainerImage\x12\"\n" +
 	"\bregistry\x18\x01 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\bregistry\x12$\n" +
 	"\tnamespace\x18\x02 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\tnamespace\x12\x1a\n" +
-	"\x04name\x18\x03 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\x04nameB`Z^github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1;oscarcfgpbv1b\x06proto3"
+	"\x04name\x18\x03 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\x04name\x12@\n" +
+	"\x04auth\x18\x04 \x01(\v2,.opensourcecorp.oscar.config.v1.RegistryAuthR\x04auth\x12$\n" +
+	"\rcontainerfile\x18\x05 \x01(\tR\rcontainerfile\x12\x18\n" +
+	"\acontext\x18\x06 \x01(\tR\acontext\x12\\\n" +
+	"\n" +
+	"build_args\x18\a \x03(\v2=.opensourcecorp.oscar.config.v1.ContainerImage.BuildArgsEntryR\tbuildArgs\x12\x16\n" +
+	"\x06target\x18\b \x01(\tR\x06target\x12;\n" +
+	"\abuilder\x18\t \x01(\tB!\xbaH\x1e\xd8\x01\x01r\x19R\x06dockerR\x06podmanR\abuildahR\abuilder\x12G\n" +
+	"\acompose\x18\n" +
+	" \x01(\v2-.opensourcecorp.oscar.config.v1.ComposeSourceR\acompose\x12O\n" +
+	"\tplatforms\x18\v \x03(\tB1\xbaH.\x92\x01+\x18\x01\"'r%2#^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$R\tplatforms\x12=\n" +
+	"\x04tags\x18\f \x01(\v2).opensourcecorp.oscar.config.v1.TagPolicyR\x04tags\x12N\n" +
+	"\x0eemulator_image\x18\r \x01(\tB'\xbaH$\xd8\x01\x01r\x1f2\x1d^[^@\\s]+@sha256:[a-f0-9]{64}$R\remulatorImage\x1a<\n" +
+	"\x0eBuildArgsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x02\n" +
+	"\tTagPolicy\x12@\n" +
+	"\x0erelease_branch\x18\x01 \x01(\tB\x19\xbaH\x16r\x142\x12^[A-Za-z0-9._/-]*$R\rreleaseBranch\x12c\n" +
+	"\arelease\x18\x02 \x03(\tBI\xbaHF\x92\x01C\x18\x01\"?r=R\aversionR\vmajor_minorR\x06latestR\x06commitR\x06branchR\rbranch_commitR\arelease\x12_\n" +
+	"\x05other\x18\x03 \x03(\tBI\xbaHF\x92\x01C\x18\x01\"?r=R\aversionR\vmajor_minorR\x06latestR\x06commitR\x06branchR\rbranch_commitR\x05other\"E\n" +
+	"\rComposeSource\x12\x1a\n" +
+	"\x04file\x18\x01 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\x04file\x12\x18\n" +
+	"\aservice\x18\x02 \x01(\tR\aservice\"\x9f\x01\n" +
+	"\fRegistryAuth\x12I\n" +
+	"\bprovider\x18\x01 \x01(\tB-\xbaH*\xc8\x01\x01r%R\x04ghcrR\tdockerhubR\x03envR\rdocker-configR\bprovider\x12!\n" +
+	"\fusername_env\x18\x02 \x01(\tR\vusernameEnv\x12!\n" +
+	"\fpassword_env\x18\x03 \x01(\tR\vpasswordEnvB`Z^github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1;oscarcfgpbv1b\x06proto3"
 
 var (
 	file_opensourcecorp_oscar_config_v1_config_proto_rawDescOnce sync.Once
@@ -297,22 +838,36 @@ func file_opensourcecorp_oscar_config_v1_config_proto_rawDescGZIP() []byte {
 	return file_opensourcecorp_oscar_config_v1_config_proto_rawDescData
 }
 
-var file_opensourcecorp_oscar_config_v1_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_opensourcecorp_oscar_config_v1_config_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_opensourcecorp_oscar_config_v1_config_proto_goTypes = []any{
 	(*Config)(nil),          // 0: opensourcecorp.oscar.config.v1.Config
-	(*Deliverables)(nil),    // 1: opensourcecorp.oscar.config.v1.Deliverables
-	(*GoGitHubRelease)(nil), // 2: opensourcecorp.oscar.config.v1.GoGitHubRelease
-	(*ContainerImage)(nil),  // 3: opensourcecorp.oscar.config.v1.ContainerImage
+	(*CustomTask)(nil),      // 1: opensourcecorp.oscar.config.v1.CustomTask
+	(*Deliverables)(nil),    // 2: opensourcecorp.oscar.config.v1.Deliverables
+	(*GoGitHubRelease)(nil), // 3: opensourcecorp.oscar.config.v1.GoGitHubRelease
+	(*ContainerImage)(nil),  // 4: opensourcecorp.oscar.config.v1.ContainerImage
+	(*TagPolicy)(nil),       // 5: opensourcecorp.oscar.config.v1.TagPolicy
+	(*ComposeSource)(nil),   // 6: opensourcecorp.oscar.config.v1.ComposeSource
+	(*RegistryAuth)(nil),    // 7: opensourcecorp.oscar.config.v1.RegistryAuth
+	nil,                     // 8: opensourcecorp.oscar.config.v1.Config.TaskTimeoutsEntry
+	nil,                     // 9: opensourcecorp.oscar.config.v1.CustomTask.EnvEntry
+	nil,                     // 10: opensourcecorp.oscar.config.v1.ContainerImage.BuildArgsEntry
 }
 var file_opensourcecorp_oscar_config_v1_config_proto_depIdxs = []int32{
-	1, // 0: opensourcecorp.oscar.config.v1.Config.deliverables:type_name -> opensourcecorp.oscar.config.v1.Deliverables
-	2, // 1: opensourcecorp.oscar.config.v1.Deliverables.go_github_release:type_name -> opensourcecorp.oscar.config.v1.GoGitHubRelease
-	3, // 2: opensourcecorp.oscar.config.v1.Deliverables.container_image:type_name -> opensourcecorp.oscar.config.v1.ContainerImage
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	2,  // 0: opensourcecorp.oscar.config.v1.Config.deliverables:type_name -> opensourcecorp.oscar.config.v1.Deliverables
+	1,  // 1: opensourcecorp.oscar.config.v1.Config.custom_tasks:type_name -> opensourcecorp.oscar.config.v1.CustomTask
+	8,  // 2: opensourcecorp.oscar.config.v1.Config.task_timeouts:type_name -> opensourcecorp.oscar.config.v1.Config.TaskTimeoutsEntry
+	9,  // 3: opensourcecorp.oscar.config.v1.CustomTask.env:type_name -> opensourcecorp.oscar.config.v1.CustomTask.EnvEntry
+	3,  // 4: opensourcecorp.oscar.config.v1.Deliverables.go_github_release:type_name -> opensourcecorp.oscar.config.v1.GoGitHubRelease
+	4,  // 5: opensourcecorp.oscar.config.v1.Deliverables.container_image:type_name -> opensourcecorp.oscar.config.v1.ContainerImage
+	7,  // 6: opensourcecorp.oscar.config.v1.ContainerImage.auth:type_name -> opensourcecorp.oscar.config.v1.RegistryAuth
+	10, // 7: opensourcecorp.oscar.config.v1.ContainerImage.build_args:type_name -> opensourcecorp.oscar.config.v1.ContainerImage.BuildArgsEntry
+	6,  // 8: opensourcecorp.oscar.config.v1.ContainerImage.compose:type_name -> opensourcecorp.oscar.config.v1.ComposeSource
+	5,  // 9: opensourcecorp.oscar.config.v1.ContainerImage.tags:type_name -> opensourcecorp.oscar.config.v1.TagPolicy
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_opensourcecorp_oscar_config_v1_config_proto_init() }
@@ -326,7 +881,7 @@ func file_opensourcecorp_oscar_config_v1_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_opensourcecorp_oscar_config_v1_config_proto_rawDesc), len(file_opensourcecorp_oscar_config_v1_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
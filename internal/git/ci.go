@@ -3,9 +3,8 @@ package igit
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"slices"
-	"strings"
+	"sync"
 
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 )
@@ -17,106 +16,280 @@ type CI struct {
 	BaselineStatus Status
 	// CurrentStatus is the latest-available Git status, which may differ from the baseline.
 	CurrentStatus Status
+
+	// mu guards the fields below, as well as the baseline & current statuses, since CI tasks may
+	// start & finish concurrently.
+	mu sync.Mutex
+	// running holds the IDs of tasks that have started but not yet finished.
+	running map[string]struct{}
+	// suspects holds the IDs of every task that was running at any point since the last status
+	// check, i.e. every task that could have produced a change seen by the next check.
+	suspects map[string]struct{}
+	// mutating holds the IDs of tasks that are expected to rewrite files, like formatters.
+	mutating map[string]struct{}
+	// blamed holds any changes attributed to a task that has not yet finished.
+	blamed map[string]Changes
+	// ignored holds paths that tasks are known to create & then remove on their own while they run
+	// (like a tool's config file), and so should never be reported as changes.
+	ignored []string
+	// initialTree & baselineTree are snapshots of the work tree (see [snapshotTree]) from before any
+	// tasks ran, and from when the baseline status was last set, to diff changes against.
+	initialTree  string
+	baselineTree string
+	// changedPaths holds every path that has been reported as changed so far during the run.
+	changedPaths []string
+}
+
+// Changes describes files that changed during a CI task's run.
+type Changes struct {
+	Status
+	// The IDs of every task that was running while the changes were made, any of which may have
+	// made them.
+	Suspects []string
+	// A unified diff of the changes, against the files as they were right before they were made, in
+	// a format that `git apply` accepts.
+	Patch string
+}
+
+// HasChanges reports whether any files were changed.
+func (c Changes) HasChanges() bool {
+	return len(c.Entries) > 0
+}
+
+// ChangesError is returned when a CI task changed files, which always fails the task.
+type ChangesError struct {
+	Changes Changes
 }
 
-// NewForCI returns Git information for CI tasks.
-func NewForCI(ctx context.Context) (*CI, error) {
-	status, err := getRawStatus(ctx)
+// Error implements the error interface.
+func (e *ChangesError) Error() string {
+	msg := fmt.Sprintf(
+		"Files ~CHANGED~ during run: %+v\nFiles +CREATED+ during run: %+v",
+		e.Changes.Changed(), e.Changes.Untracked(),
+	)
+
+	if len(e.Changes.Suspects) > 1 {
+		msg += fmt.Sprintf(
+			"\nNOTE: these Tasks were running at the same time, and any of them may have made the above changes: %+v",
+			e.Changes.Suspects,
+		)
+	}
+
+	return msg
+}
+
+// NewForCI returns Git information for CI tasks. Any provided ignoredPaths will never be reported as
+// changes.
+func NewForCI(ctx context.Context, ignoredPaths ...string) (*CI, error) {
+	status, err := getStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := snapshotTree(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	return &CI{
 		BaselineStatus: status,
+		running:        make(map[string]struct{}),
+		suspects:       make(map[string]struct{}),
+		mutating:       make(map[string]struct{}),
+		blamed:         make(map[string]Changes),
+		ignored:        ignoredPaths,
+		initialTree:    tree,
+		baselineTree:   tree,
+		changedPaths:   make([]string, 0),
 	}, nil
 }
 
-// Update recalculates various Git metadata, respecting any existing baseline values set in [NewForCI].
-func (g *CI) Update(ctx context.Context) error {
-	status, err := getRawStatus(ctx)
+// TaskStarted records that the task identified by `id` has started, so that any changes seen before
+// it finishes can be attributed to it. A mutating task is one that's expected to rewrite files.
+func (g *CI) TaskStarted(id string, mutating bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.running[id] = struct{}{}
+	g.suspects[id] = struct{}{}
+	if mutating {
+		g.mutating[id] = struct{}{}
+	}
+}
+
+// TaskFinished checks for changes to Git status since the last check, and returns any changes
+// attributed to the task identified by `id`.
+//
+// When tasks run concurrently, a change can't be traced back to exactly one task, so every task
+// that was running while a change was made is held responsible for it -- either now, or when it
+// finishes. If any of those tasks are mutating ones, only they are held responsible, so that e.g. a
+// linter isn't failed for a formatter's changes in another group. Once checked, the status becomes
+// the new baseline, so that a change is only ever reported once.
+func (g *CI) TaskFinished(ctx context.Context, id string) (Changes, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.running, id)
+
+	status, tree, err := g.updateStatus(ctx)
 	if err != nil {
-		return fmt.Errorf("getting Git status: %w", err)
+		return Changes{}, err
 	}
 
-	untrackedFiles := make([]string, 0)
-	diff := make([]string, 0)
+	if len(g.CurrentStatus.Entries) > 0 {
+		paths := entryPaths(g.CurrentStatus.Entries)
+		patch, err := diffTrees(ctx, g.baselineTree, tree, paths)
+		if err != nil {
+			return Changes{}, err
+		}
 
-	for _, line := range status.Diff {
-		if !slices.Contains(g.BaselineStatus.Diff, line) {
-			filename := regexp.MustCompile(`^ ?[A-Z]+ `).ReplaceAllString(line, "")
-			diff = append(diff, filename)
+		for _, path := range paths {
+			if !slices.Contains(g.changedPaths, path) {
+				g.changedPaths = append(g.changedPaths, path)
+			}
 		}
+
+		suspects := g.narrowedSuspects()
+
+		for _, suspect := range suspects {
+			blamed := g.blamed[suspect]
+			blamed.Entries = append(blamed.Entries, g.CurrentStatus.Entries...)
+			blamed.Patch += patch
+			for _, other := range suspects {
+				if !slices.Contains(blamed.Suspects, other) {
+					blamed.Suspects = append(blamed.Suspects, other)
+				}
+			}
+			g.blamed[suspect] = blamed
+		}
+
+		// Reset the baseline, so this change isn't reported again
+		g.baselineTree = tree
+		g.BaselineStatus = status
 	}
 
-	for _, line := range status.UntrackedFiles {
-		if !slices.Contains(g.BaselineStatus.UntrackedFiles, line) {
-			filename := strings.ReplaceAll(line, "?? ", "")
-			untrackedFiles = append(diff, filename)
+	// Only the tasks that are still running could be responsible for changes seen at the next check
+	g.suspects = make(map[string]struct{})
+	for running := range g.running {
+		g.suspects[running] = struct{}{}
+	}
+
+	changes := g.blamed[id]
+	delete(g.blamed, id)
+	delete(g.mutating, id)
+
+	return changes, nil
+}
+
+// narrowedSuspects returns the sorted IDs of the tasks to hold responsible for a change: the
+// mutating suspects if there are any, and every suspect otherwise.
+func (g *CI) narrowedSuspects() []string {
+	all := make([]string, 0, len(g.suspects))
+	mutating := make([]string, 0)
+	for suspect := range g.suspects {
+		all = append(all, suspect)
+		if _, found := g.mutating[suspect]; found {
+			mutating = append(mutating, suspect)
 		}
 	}
 
-	g.CurrentStatus = Status{
-		Diff:           diff,
-		UntrackedFiles: untrackedFiles,
+	out := all
+	if len(mutating) > 0 {
+		out = mutating
 	}
+	slices.Sort(out)
 
-	return nil
+	return out
 }
 
-// StatusHasChanged informs the caller of whether or not the [Status] now differs from the baseline.
-func (g *CI) StatusHasChanged(ctx context.Context) (bool, error) {
-	if err := g.updateStatus(ctx); err != nil {
-		return false, err
+// Patch returns a unified diff of every change reported so far during the run, against the files as
+// they were before any tasks ran. Applying it with `git apply` to the original files reproduces
+// every change that tasks made. It returns an empty string if no changes were reported.
+func (g *CI) Patch(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.changedPaths) == 0 {
+		return "", nil
 	}
 
-	iprint.Debugf("len(g.CurrentStatus.Diff) = %d\n", len(g.CurrentStatus.Diff))
-	iprint.Debugf("len(g.BaselineStatusForCI.Diff) = %d\n", len(g.BaselineStatus.Diff))
-	iprint.Debugf("len(g.CurrentStatus.UntrackedFiles) = %d\n", len(g.CurrentStatus.UntrackedFiles))
-	iprint.Debugf("len(g.BaselineStatusForCI.UntrackedFiles) = %d\n", len(g.BaselineStatus.UntrackedFiles))
+	tree, err := snapshotTree(ctx)
+	if err != nil {
+		return "", err
+	}
 
-	statusChanged := (len(g.CurrentStatus.Diff)+len(g.BaselineStatus.Diff)) != len(g.BaselineStatus.Diff) ||
-		(len(g.CurrentStatus.UntrackedFiles)+len(g.BaselineStatus.UntrackedFiles)) != len(g.BaselineStatus.UntrackedFiles)
+	return diffTrees(ctx, g.initialTree, tree, g.changedPaths)
+}
 
-	iprint.Debugf("statusChanged: %v\n", statusChanged)
+// entryPaths returns every path that the provided entries touch, including the original paths of
+// any renamed or copied files.
+func entryPaths(entries []StatusEntry) []string {
+	out := make([]string, 0)
+	for _, e := range entries {
+		for _, path := range []string{e.OrigPath, e.Path} {
+			if path != "" && !slices.Contains(out, path) {
+				out = append(out, path)
+			}
+		}
+	}
 
-	return statusChanged, nil
+	return out
 }
 
-// updateStatus updates the tracked Git status so that it can be compared against the baseline.
-func (g *CI) updateStatus(ctx context.Context) error {
-	// So any future debug logs have a line break in them
-	iprint.Debugf("\n")
+// StatusHasChanged informs the caller of whether or not any files now differ from the baseline.
+func (g *CI) StatusHasChanged(ctx context.Context) (bool, error) {
+	if _, _, err := g.updateStatus(ctx); err != nil {
+		return false, err
+	}
 
-	iprint.Debugf("OLD git: %+v\n", g)
+	statusChanged := len(g.CurrentStatus.Entries) > 0
+	iprint.Debugf("statusChanged: %v\n", statusChanged)
+
+	return statusChanged, nil
+}
 
-	status, err := getRawStatus(ctx)
+// updateStatus sets the current Git status to the entries for every path that changed since the
+// baseline, and returns the full status & a snapshot of the work tree that it compared against the
+// baseline's. Comparing snapshots, rather than just status entries, also catches files that were
+// already changed in the baseline & then changed again, since their entries stay the same.
+func (g *CI) updateStatus(ctx context.Context) (Status, string, error) {
+	status, err := getStatus(ctx)
 	if err != nil {
-		return fmt.Errorf("getting Git status: %w", err)
+		return Status{}, "", fmt.Errorf("getting Git status: %w", err)
 	}
 
-	diff := make([]string, 0)
-	untrackedFiles := make([]string, 0)
+	tree, err := snapshotTree(ctx)
+	if err != nil {
+		return Status{}, "", err
+	}
 
-	for _, line := range status.Diff {
-		filename := regexp.MustCompile(`^( +)?[A-Z]+ +`).ReplaceAllString(line, "")
-		if !slices.Contains(g.BaselineStatus.Diff, filename) {
-			diff = append(diff, filename)
-		}
+	changedPaths, err := treeDiffPaths(ctx, g.baselineTree, tree)
+	if err != nil {
+		return Status{}, "", err
 	}
 
-	for _, line := range status.UntrackedFiles {
-		filename := strings.ReplaceAll(line, "?? ", "")
-		if !slices.Contains(g.BaselineStatus.UntrackedFiles, filename) {
-			untrackedFiles = append(diff, filename)
+	// Entries that changed in any way count too, e.g. if a file was staged
+	entries := status.Difference(g.BaselineStatus).Entries
+	for _, path := range changedPaths {
+		if slices.ContainsFunc(entries, func(e StatusEntry) bool { return e.Path == path || e.OrigPath == path }) {
+			continue
 		}
-	}
 
-	g.CurrentStatus = Status{
-		Diff:           diff,
-		UntrackedFiles: untrackedFiles,
+		// A file with no entry now (e.g. one changed back to match HEAD) is described by its baseline
+		// entry instead
+		for _, st := range []Status{status, g.BaselineStatus} {
+			i := slices.IndexFunc(st.Entries, func(e StatusEntry) bool { return e.Path == path || e.OrigPath == path })
+			if i != -1 {
+				entries = append(entries, st.Entries[i])
+				break
+			}
+		}
 	}
 
-	iprint.Debugf("NEW git: %+v\n", g)
+	g.CurrentStatus = Status{Entries: slices.DeleteFunc(entries, func(e StatusEntry) bool {
+		return slices.Contains(g.ignored, e.Path)
+	})}
+	iprint.Debugf("Git status changes since baseline: %+v\n", g.CurrentStatus.Entries)
 
-	return nil
+	return status, tree, nil
 }
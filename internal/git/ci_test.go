@@ -0,0 +1,139 @@
+package igit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCITaskFinished(t *testing.T) {
+	dir, git := newTestRepo(t, map[string]string{
+		"a.txt":     "a\n",
+		"b.txt":     "b\n",
+		"staged.go": "s\n",
+	})
+	ctx := system.WithWorkDir(t.Context(), dir)
+
+	// Changes that were already there before any Tasks ran
+	writeTestFile(t, filepath.Join(dir, "a.txt"), "already changed\n")
+	writeTestFile(t, filepath.Join(dir, "staged.go"), "staged\n")
+	git("add", "staged.go")
+	writeTestFile(t, filepath.Join(dir, "untracked.txt"), "already here\n")
+
+	ci, err := NewForCI(ctx, "tool-config.yaml")
+	require.NoError(t, err)
+
+	ci.TaskStarted("Format", true)
+	writeTestFile(t, filepath.Join(dir, "b.txt"), "formatted\n")
+	writeTestFile(t, filepath.Join(dir, "staged.go"), "formatted\n")
+	writeTestFile(t, filepath.Join(dir, "created.txt"), "new\n")
+	writeTestFile(t, filepath.Join(dir, "tool-config.yaml"), "ignored\n")
+
+	changes, err := ci.TaskFinished(ctx, "Format")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"b.txt", "staged.go"}, Paths(changes.Changed()))
+	assert.Equal(t, []string{"created.txt"}, Paths(changes.Untracked()))
+
+	// The diff is against the files as they were before the Task ran, not as committed
+	assert.Contains(t, changes.Patch, "-b\n+formatted\n")
+	assert.Contains(t, changes.Patch, "-staged\n+formatted\n")
+	assert.Contains(t, changes.Patch, "+++ b/created.txt\n@@ -0,0 +1 @@\n+new\n")
+	assert.NotContains(t, changes.Patch, "a.txt")
+	assert.NotContains(t, changes.Patch, "tool-config.yaml")
+
+	// Snapshotting the work tree for the diff must leave what's staged alone
+	staged, err := system.RunHostCommand(ctx, []string{"git", "show", ":staged.go"})
+	require.NoError(t, err)
+	assert.Equal(t, "staged", staged)
+
+	// Once reported, the same changes become part of the baseline, so they aren't blamed on the next
+	// Task too
+	ci.TaskStarted("Lint", false)
+
+	changes, err = ci.TaskFinished(ctx, "Lint")
+	require.NoError(t, err)
+	assert.False(t, changes.HasChanges())
+
+	// The whole run's patch can be applied to the files as they were before it
+	patch, err := ci.Patch(ctx)
+	require.NoError(t, err)
+	writeTestFile(t, filepath.Join(dir, "run.patch"), patch)
+	git("apply", "--reverse", "run.patch")
+	assert.FileExists(t, filepath.Join(dir, "untracked.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "created.txt"))
+	b, err := os.ReadFile(filepath.Join(dir, "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(b))
+}
+
+func TestCITaskFinishedDirtyFileRewritten(t *testing.T) {
+	dir, _ := newTestRepo(t, map[string]string{"a.go": "a\n", "b.go": "b\n"})
+	ctx := system.WithWorkDir(t.Context(), dir)
+
+	// Already changed before any Tasks ran, so its status entry stays the same when it's rewritten
+	writeTestFile(t, filepath.Join(dir, "a.go"), "dirty\n")
+
+	ci, err := NewForCI(ctx)
+	require.NoError(t, err)
+
+	ci.TaskStarted("Format", true)
+	writeTestFile(t, filepath.Join(dir, "a.go"), "formatted\n")
+
+	changes, err := ci.TaskFinished(ctx, "Format")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, Paths(changes.Changed()))
+	assert.Contains(t, changes.Patch, "-dirty\n+formatted\n")
+
+	// Changing it back to match HEAD is a change too, even though it then has no status entry
+	ci.TaskStarted("Format", true)
+	writeTestFile(t, filepath.Join(dir, "a.go"), "a\n")
+
+	changes, err = ci.TaskFinished(ctx, "Format")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, Paths(changes.Changed()))
+	assert.Contains(t, changes.Patch, "-formatted\n+a\n")
+
+	ci.TaskStarted("Lint", false)
+	changes, err = ci.TaskFinished(ctx, "Lint")
+	require.NoError(t, err)
+	assert.False(t, changes.HasChanges())
+}
+
+func TestCITaskFinishedBlamesMutatingTasks(t *testing.T) {
+	dir, _ := newTestRepo(t, map[string]string{"a.txt": "a\n"})
+	ctx := system.WithWorkDir(t.Context(), dir)
+
+	ci, err := NewForCI(ctx)
+	require.NoError(t, err)
+
+	ci.TaskStarted("Lint", false)
+	ci.TaskStarted("Format", true)
+	writeTestFile(t, filepath.Join(dir, "a.txt"), "formatted\n")
+
+	// A check-only Task isn't blamed for changes while a mutating one was running
+	changes, err := ci.TaskFinished(ctx, "Lint")
+	require.NoError(t, err)
+	assert.False(t, changes.HasChanges())
+
+	changes, err = ci.TaskFinished(ctx, "Format")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, Paths(changes.Changed()))
+	assert.Equal(t, []string{"Format"}, changes.Suspects)
+
+	// Without any mutating Tasks running, every Task that was running is blamed
+	ci.TaskStarted("Lint", false)
+	ci.TaskStarted("Test", false)
+	writeTestFile(t, filepath.Join(dir, "b.txt"), "created\n")
+
+	changes, err = ci.TaskFinished(ctx, "Lint")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Lint", "Test"}, changes.Suspects)
+	changes, err = ci.TaskFinished(ctx, "Test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.txt"}, Paths(changes.Untracked()))
+}
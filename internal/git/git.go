@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 
 	iprint "github.com/opensourcecorp/oscar/internal/print"
@@ -25,14 +26,6 @@ type Git struct {
 	IsDirty bool
 }
 
-// Status holds various pieces of information about Git status.
-type Status struct {
-	// The list of modified files.
-	Diff []string
-	// The list of untracked files.
-	UntrackedFiles []string
-}
-
 // New returns a populated [Git].
 func New(ctx context.Context) (*Git, error) {
 	root, err := system.RunCommand(ctx, []string{"git", "rev-parse", "--show-toplevel"})
@@ -59,22 +52,17 @@ func New(ctx context.Context) (*Git, error) {
 	}
 	iprint.Debugf("latest Git commit: '%s'\n", latestCommit)
 
-	gitStatus, err := getRawStatus(ctx)
+	gitStatus, err := getStatus(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("getting Git status: %w", err)
 	}
 
-	var isDirty bool
-	if len(gitStatus.Diff) > 0 || len(gitStatus.UntrackedFiles) > 0 {
-		isDirty = true
-	}
-
 	out := Git{
 		Root:         root,
 		Branch:       branch,
 		LatestTag:    latestTag,
 		LatestCommit: latestCommit,
-		IsDirty:      isDirty,
+		IsDirty:      len(gitStatus.Entries) > 0,
 	}
 	iprint.Debugf("Git: %+v\n", out)
 
@@ -104,34 +92,148 @@ func (g *Git) String() string {
 	return out
 }
 
-// getRawStatus returns a slightly-modified "git status" output, so that calling tools can parse it
-// more easily.
-func getRawStatus(ctx context.Context) (Status, error) {
-	outputBytes, err := system.RunCommand(ctx, []string{"git", "status", "--porcelain"})
+// ChangedFilesSince returns the paths of every file that has changed since the provided ref
+// diverged from HEAD (i.e. since their merge base), including any uncommitted & untracked files.
+// Paths are relative to the current directory, and deleted files are not included.
+func ChangedFilesSince(ctx context.Context, ref string) ([]string, error) {
+	mergeBase, err := system.RunCommand(ctx, []string{"git", "merge-base", ref, "HEAD"})
+	if err != nil {
+		return nil, fmt.Errorf("finding merge base of '%s' and HEAD: %w", ref, err)
+	}
+	iprint.Debugf("merge base of '%s' and HEAD: '%s'\n", ref, mergeBase)
+
+	diff, err := system.RunCommand(ctx, []string{
+		"git", "diff", "--name-only", "--relative", "--diff-filter=d", mergeBase,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting changed files: %w", err)
+	}
+
+	untracked, err := system.RunCommand(ctx, []string{
+		"git", "ls-files", "--others", "--exclude-standard",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting untracked files: %w", err)
+	}
+
+	out := make([]string, 0)
+	for _, line := range slices.Concat(strings.Split(diff, "\n"), strings.Split(untracked, "\n")) {
+		if line != "" && !slices.Contains(out, line) {
+			out = append(out, line)
+		}
+	}
+	slices.Sort(out)
+	iprint.Debugf("files changed since '%s': %v\n", ref, out)
+
+	return out, nil
+}
+
+// StagedFiles returns the paths of every file that is staged in the Git index, i.e. that would be
+// part of the next commit. Paths are relative to the current directory, and deleted files are not
+// included.
+func StagedFiles(ctx context.Context) ([]string, error) {
+	output, err := system.RunHostCommand(ctx, []string{
+		"git", "diff", "--cached", "--name-only", "--relative", "--diff-filter=d", "-z",
+	})
 	if err != nil {
-		return Status{}, fmt.Errorf("getting git status output: %w", err)
+		return nil, fmt.Errorf("getting staged files: %w", err)
 	}
 
-	output := string(outputBytes)
-	outputSplit := strings.Split(output, "\n")
+	out := make([]string, 0)
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			out = append(out, path)
+		}
+	}
+	slices.Sort(out)
+	iprint.Debugf("staged files: %v\n", out)
+
+	return out, nil
+}
 
-	untrackedFiles := make([]string, 0)
-	diff := make([]string, 0)
-	for _, line := range outputSplit {
-		if line == "" {
-			continue
+// LocalBaseBranchRef returns a ref for the provided base branch without touching the network: the
+// local branch if there is one, otherwise its remote-tracking branch from origin. If neither exists,
+// it returns false.
+func LocalBaseBranchRef(ctx context.Context, branch string) (string, bool) {
+	for _, ref := range []string{branch, "origin/" + branch} {
+		_, err := system.RunHostCommand(ctx, []string{"git", "rev-parse", "--verify", "--quiet", ref + "^{commit}"})
+		if err == nil {
+			return ref, true
 		}
-		if strings.HasPrefix(line, "??") {
-			filename := strings.ReplaceAll(line, "?? ", "")
-			untrackedFiles = append(untrackedFiles, filename)
-		} else {
-			filename := regexp.MustCompile(`^( +)?[A-Z]+ +`).ReplaceAllString(line, "")
-			diff = append(diff, filename)
+	}
+
+	return "", false
+}
+
+// BaseBranchRef returns a ref for the provided base branch, like [LocalBaseBranchRef]. If neither
+// exists, the branch is fetched from origin first, so that the network is only needed when the
+// branch isn't already available locally (e.g. in CI systems that only check out the branch being
+// built).
+func BaseBranchRef(ctx context.Context, branch string) (string, error) {
+	if ref, ok := LocalBaseBranchRef(ctx, branch); ok {
+		return ref, nil
+	}
+	remoteRef := "origin/" + branch
+	iprint.Debugf("base branch '%s' not found locally, so fetching it from origin\n", branch)
+
+	args := []string{"git", "fetch", "--quiet", "--no-tags"}
+	// Don't pull down the base branch's entire history into a shallow clone
+	shallow, err := system.RunHostCommand(ctx, []string{"git", "rev-parse", "--is-shallow-repository"})
+	if err == nil && shallow == "true" {
+		args = append(args, "--depth=1")
+	}
+	args = append(args, "origin", fmt.Sprintf("+refs/heads/%s:refs/remotes/%s", branch, remoteRef))
+
+	if _, err := system.RunHostCommand(ctx, args); err != nil {
+		return "", fmt.Errorf("fetching base branch '%s' from origin: %w", branch, err)
+	}
+
+	return remoteRef, nil
+}
+
+// ShowFile returns the contents of the file at path as of the provided ref. Paths are relative to
+// the current directory.
+func ShowFile(ctx context.Context, ref string, path string) (string, error) {
+	out, err := system.RunHostCommand(ctx, []string{"git", "show", ref + ":./" + path})
+	if err != nil {
+		return "", fmt.Errorf("reading '%s' at '%s': %w", path, ref, err)
+	}
+
+	return out, nil
+}
+
+// LatestReachableTag returns the most recent tag that is reachable from HEAD, or an empty string if
+// there isn't one.
+func LatestReachableTag(ctx context.Context) string {
+	tag, err := system.RunHostCommand(ctx, []string{"git", "describe", "--tags", "--abbrev=0"})
+	if err != nil {
+		iprint.Debugf("no tag reachable from HEAD: %v\n", err)
+		return ""
+	}
+
+	return tag
+}
+
+// CommitMessagesSince returns the full message of every commit reachable from HEAD but not from the
+// provided ref, newest first. If ref is empty, every commit reachable from HEAD is returned.
+func CommitMessagesSince(ctx context.Context, ref string) ([]string, error) {
+	revRange := "HEAD"
+	if ref != "" {
+		revRange = ref + "..HEAD"
+	}
+
+	// NOTE: messages can span multiple lines, so they're separated by NUL characters instead
+	output, err := system.RunHostCommand(ctx, []string{"git", "log", "--format=%B%x00", revRange})
+	if err != nil {
+		return nil, fmt.Errorf("getting commit messages since '%s': %w", ref, err)
+	}
+
+	out := make([]string, 0)
+	for _, message := range strings.Split(output, "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			out = append(out, message)
 		}
 	}
 
-	return Status{
-		Diff:           diff,
-		UntrackedFiles: untrackedFiles,
-	}, nil
+	return out, nil
 }
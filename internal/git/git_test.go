@@ -1,9 +1,12 @@
 package igit
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/opensourcecorp/oscar/internal/system"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSanitizedBranch(t *testing.T) {
@@ -37,3 +40,49 @@ func TestSanitizedBranch(t *testing.T) {
 		})
 	}
 }
+
+func TestBaseBranchRef(t *testing.T) {
+	originDir, originGit := newTestRepo(t, nil)
+	originGit("branch", "--move", "develop")
+
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	_, err := system.RunHostCommand(t.Context(), []string{
+		"git", "clone", "--quiet", "--branch=develop", originDir, cloneDir,
+	})
+	require.NoError(t, err)
+
+	ctx := system.WithWorkDir(t.Context(), cloneDir)
+
+	t.Run("local branch", func(t *testing.T) {
+		ref, err := BaseBranchRef(ctx, "develop")
+		require.NoError(t, err)
+		assert.Equal(t, "develop", ref)
+	})
+
+	t.Run("local lookup doesn't fetch", func(t *testing.T) {
+		originGit("branch", "release")
+
+		ref, ok := LocalBaseBranchRef(ctx, "develop")
+		assert.True(t, ok)
+		assert.Equal(t, "develop", ref)
+
+		_, ok = LocalBaseBranchRef(ctx, "release")
+		assert.False(t, ok)
+	})
+
+	t.Run("fetched when missing locally", func(t *testing.T) {
+		ref, err := BaseBranchRef(ctx, "release")
+		require.NoError(t, err)
+		assert.Equal(t, "origin/release", ref)
+
+		// Now that it's been fetched, it's found locally too
+		ref, ok := LocalBaseBranchRef(ctx, "release")
+		assert.True(t, ok)
+		assert.Equal(t, "origin/release", ref)
+	})
+
+	t.Run("missing everywhere", func(t *testing.T) {
+		_, err := BaseBranchRef(ctx, "does-not-exist")
+		assert.Error(t, err)
+	})
+}
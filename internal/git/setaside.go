@@ -0,0 +1,168 @@
+package igit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+// unstagedPatchName is the name of the file in the repo's Git directory that holds unstaged changes
+// while they're set aside. It's kept there instead of in a temporary directory, so that the changes
+// can still be found & restored by hand if oscar is killed before it can restore them itself.
+const unstagedPatchName = "oscar-unstaged.patch"
+
+// SetAside holds the unstaged changes that [SetAsideUnstaged] removed from the work tree.
+type SetAside struct {
+	// topLevel is the repo's root directory, since patches must be applied from there.
+	topLevel string
+	// patchPath is the path to the patch of the set-aside changes, or empty if there were none.
+	patchPath string
+	// paths are the files in the patch, relative to topLevel.
+	paths []string
+}
+
+// SetAsideUnstaged removes every unstaged change to tracked files from the work tree, so that those
+// files match exactly what's staged, and returns a [SetAside] to put the changes back with.
+// Untracked files are left alone. The changes are saved as a patch in the repo's Git directory
+// before anything is removed, so that they're never lost, even if they can't be restored.
+func SetAsideUnstaged(ctx context.Context) (*SetAside, error) {
+	topLevel, err := system.RunHostCommand(ctx, []string{"git", "rev-parse", "--show-toplevel"})
+	if err != nil {
+		return nil, fmt.Errorf("finding Git repo root: %w", err)
+	}
+	ctx = system.WithWorkDir(ctx, topLevel)
+
+	patchPath, err := system.RunHostCommand(ctx, []string{"git", "rev-parse", "--git-path", unstagedPatchName})
+	if err != nil {
+		return nil, fmt.Errorf("finding Git directory: %w", err)
+	}
+	patchPath = system.ResolvePath(ctx, patchPath)
+
+	if _, err := os.Stat(patchPath); err == nil {
+		return nil, fmt.Errorf(
+			"unstaged changes from an earlier run that was cut short are still saved in '%s' -- restore them with 'git apply %s' and then remove that file",
+			patchPath, patchPath,
+		)
+	}
+
+	// NOTE: the user's Git config can change diff output in ways that `git apply` can't read back,
+	// so anything like that is set explicitly. The diff is written straight to the file, since
+	// command output can also have other messages from Git mixed in.
+	_, err = system.RunHostCommand(ctx, []string{
+		"git", "diff", "--binary", "--no-color", "--no-ext-diff", "--no-textconv", "--ignore-submodules",
+		"--src-prefix=a/", "--dst-prefix=b/", "--output=" + patchPath,
+	})
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("saving unstaged changes: %w", err), os.RemoveAll(patchPath))
+	}
+
+	info, err := os.Stat(patchPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading saved unstaged changes: %w", err)
+	}
+	if info.Size() == 0 {
+		if err := os.Remove(patchPath); err != nil {
+			return nil, fmt.Errorf("removing empty patch file: %w", err)
+		}
+		return &SetAside{topLevel: topLevel}, nil
+	}
+
+	paths, err := diffPaths(ctx, "--ignore-submodules")
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("listing unstaged changes: %w", err), os.Remove(patchPath))
+	}
+
+	iprint.Infof("Setting aside unstaged changes while checking staged files (saved in '%s')\n", patchPath)
+	if _, err := system.RunHostCommand(ctx, []string{"git", "checkout", "--", ":/"}); err != nil {
+		return nil, fmt.Errorf(
+			"removing unstaged changes from the work tree -- they are still saved in '%s', and can be restored with 'git apply %s': %w",
+			patchPath, patchPath, err,
+		)
+	}
+
+	return &SetAside{topLevel: topLevel, patchPath: patchPath, paths: paths}, nil
+}
+
+// Restore puts the set-aside changes back into the work tree. If they conflict with changes made to
+// the same files since they were set aside (e.g. by a formatter), the newer changes to those files
+// are discarded so that the set-aside ones can be restored as they were, and an error lists the
+// files whose changes were discarded. Changes to any other files are kept. Restoring is never cut
+// short by ctx being canceled, since it's often exactly what needs to happen when a run is
+// interrupted.
+func (s *SetAside) Restore(ctx context.Context) error {
+	if s == nil || s.patchPath == "" {
+		return nil
+	}
+	ctx = system.WithWorkDir(context.WithoutCancel(ctx), s.topLevel)
+
+	var discarded []string
+	apply := []string{"git", "apply", "--whitespace=nowarn", s.patchPath}
+	if _, err := system.RunHostCommand(ctx, apply); err != nil {
+		// The set-aside files matched the index until the run started, so any of them that don't now
+		// were changed during the run
+		pathspecs := literalPathspecs(s.paths)
+		discarded, err = diffPaths(ctx, pathspecs...)
+		if err != nil {
+			return fmt.Errorf("finding changes made during the run to files with unstaged changes: %w", err)
+		}
+		iprint.Warnf("Unstaged changes conflict with changes made during the run, so those are being rolled back: %s\n", strings.Join(discarded, ", "))
+
+		if _, err := system.RunHostCommand(ctx, slices.Concat([]string{"git", "checkout"}, pathspecs)); err != nil {
+			return fmt.Errorf("rolling back changes made during the run: %w", err)
+		}
+		if _, err := system.RunHostCommand(ctx, apply); err != nil {
+			return fmt.Errorf(
+				"restoring unstaged changes -- they are still saved in '%s', and can be restored with 'git apply %s': %w",
+				s.patchPath, s.patchPath, err,
+			)
+		}
+	}
+
+	if err := os.Remove(s.patchPath); err != nil {
+		return fmt.Errorf("removing saved unstaged changes: %w", err)
+	}
+
+	if len(discarded) > 0 {
+		return fmt.Errorf(
+			"changes made during the run to files that also have unstaged changes were discarded, so that those could be restored: %s",
+			strings.Join(discarded, ", "),
+		)
+	}
+
+	return nil
+}
+
+// diffPaths returns the paths of the files with unstaged changes, relative to the repo root, with
+// any extra args passed to `git diff`.
+func diffPaths(ctx context.Context, args ...string) ([]string, error) {
+	output, err := system.RunHostCommand(ctx, slices.Concat([]string{"git", "diff", "--name-only", "-z"}, args))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0)
+	for path := range strings.SplitSeq(output, "\x00") {
+		if path != "" {
+			out = append(out, path)
+		}
+	}
+
+	return out, nil
+}
+
+// literalPathspecs returns "--" followed by paths as pathspecs that match only those exact paths,
+// relative to the repo root.
+func literalPathspecs(paths []string) []string {
+	out := []string{"--"}
+	for _, path := range paths {
+		out = append(out, ":(top,literal)"+path)
+	}
+
+	return out
+}
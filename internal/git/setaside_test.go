@@ -0,0 +1,147 @@
+package igit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAsideUnstaged(t *testing.T) {
+	files := map[string]string{
+		"partial.go": "one\ntwo\nthree\n",
+		"staged.go":  "s\n",
+		"other.txt":  "o\n",
+		"deleted.go": "d\n",
+	}
+
+	tt := []struct {
+		Name string
+		// Changes made while the unstaged changes are set aside, e.g. by a formatter
+		Format func(t *testing.T, dir string)
+		// The files that the changes above should be reported for
+		WantChanged []string
+		// Whether the changes above to fully-staged files should still be there after restoring
+		WantKept bool
+		// The files whose changes above should be discarded while restoring, with an error
+		WantDiscarded []string
+	}{
+		{
+			Name:        "no changes",
+			Format:      func(*testing.T, string) {},
+			WantChanged: []string{},
+		},
+		{
+			Name: "changes to fully-staged file are kept",
+			Format: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "staged.go"), "formatted\n")
+			},
+			WantChanged: []string{"staged.go"},
+			WantKept:    true,
+		},
+		{
+			Name: "conflicting changes to partially-staged file are rolled back, and others are kept",
+			Format: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "partial.go"), "ONE\ntwo\nthree\n")
+				writeTestFile(t, filepath.Join(dir, "staged.go"), "formatted\n")
+			},
+			WantChanged:   []string{"partial.go", "staged.go"},
+			WantKept:      true,
+			WantDiscarded: []string{"partial.go"},
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			dir, git := newTestRepo(t, files)
+			ctx := system.WithWorkDir(t.Context(), dir)
+
+			// Stage part of one file's changes, and all of another's
+			writeTestFile(t, filepath.Join(dir, "partial.go"), "one\ntwo (staged)\nthree\n")
+			writeTestFile(t, filepath.Join(dir, "staged.go"), "staged\n")
+			git("add", "partial.go", "staged.go")
+			writeTestFile(t, filepath.Join(dir, "partial.go"), "one (unstaged)\ntwo (staged)\nthree\n")
+			writeTestFile(t, filepath.Join(dir, "other.txt"), "unstaged\n")
+			require.NoError(t, os.Remove(filepath.Join(dir, "deleted.go")))
+			writeTestFile(t, filepath.Join(dir, "untracked.txt"), "untracked\n")
+
+			indexBefore, err := system.RunHostCommand(ctx, []string{"git", "diff", "--cached"})
+			require.NoError(t, err)
+
+			setAside, err := SetAsideUnstaged(ctx)
+			require.NoError(t, err)
+
+			// The work tree now matches the index, apart from untracked files
+			assertFile(t, dir, "partial.go", "one\ntwo (staged)\nthree\n")
+			assertFile(t, dir, "other.txt", "o\n")
+			assertFile(t, dir, "deleted.go", "d\n")
+			assertFile(t, dir, "untracked.txt", "untracked\n")
+			assert.FileExists(t, filepath.Join(dir, ".git", unstagedPatchName))
+
+			// Running again before restoring would set aside the wrong changes
+			_, err = SetAsideUnstaged(ctx)
+			assert.Error(t, err)
+
+			// Changes to staged files count as changes, but nothing that was set aside does
+			ci, err := NewForCI(ctx)
+			require.NoError(t, err)
+			ci.TaskStarted("Format", true)
+			s.Format(t, dir)
+			changes, err := ci.TaskFinished(ctx, "Format")
+			require.NoError(t, err)
+			assert.Equal(t, s.WantChanged, Paths(changes.Entries))
+
+			err = setAside.Restore(ctx)
+			if len(s.WantDiscarded) > 0 {
+				require.Error(t, err)
+				for _, path := range s.WantDiscarded {
+					assert.ErrorContains(t, err, path)
+				}
+				assert.NotContains(t, err.Error(), "staged.go")
+			} else {
+				require.NoError(t, err)
+			}
+
+			wantPartial := "one (unstaged)\ntwo (staged)\nthree\n"
+			assertFile(t, dir, "partial.go", wantPartial)
+			assertFile(t, dir, "other.txt", "unstaged\n")
+			assert.NoFileExists(t, filepath.Join(dir, "deleted.go"))
+			assert.NoFileExists(t, filepath.Join(dir, ".git", unstagedPatchName))
+			if s.WantKept {
+				assertFile(t, dir, "staged.go", "formatted\n")
+			} else {
+				assertFile(t, dir, "staged.go", "staged\n")
+			}
+
+			indexAfter, err := system.RunHostCommand(ctx, []string{"git", "diff", "--cached"})
+			require.NoError(t, err)
+			assert.Equal(t, indexBefore, indexAfter)
+		})
+	}
+}
+
+func TestSetAsideUnstagedNothingUnstaged(t *testing.T) {
+	dir, git := newTestRepo(t, map[string]string{"a.go": "a\n"})
+	ctx := system.WithWorkDir(t.Context(), dir)
+
+	writeTestFile(t, filepath.Join(dir, "a.go"), "staged\n")
+	git("add", "a.go")
+
+	setAside, err := SetAsideUnstaged(ctx)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, ".git", unstagedPatchName))
+
+	require.NoError(t, setAside.Restore(ctx))
+	assertFile(t, dir, "a.go", "staged\n")
+}
+
+// assertFile asserts that the file at path under dir has the wanted contents.
+func assertFile(t *testing.T, dir string, path string, want string) {
+	t.Helper()
+	got, err := os.ReadFile(filepath.Join(dir, path))
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
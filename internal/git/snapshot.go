@@ -0,0 +1,109 @@
+package igit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+// snapshotTree writes the current contents of the work tree -- including untracked files that
+// aren't ignored -- to the repo's object store as a tree, and returns the tree's ID. It does so via
+// a temporary copy of the index, so that the real index (i.e. what's staged) is never touched.
+func snapshotTree(ctx context.Context) (tree string, err error) {
+	indexPath, err := system.RunHostCommand(ctx, []string{"git", "rev-parse", "--git-path", "index"})
+	if err != nil {
+		return "", fmt.Errorf("finding Git index: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "oscar-git-snapshot-")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer func() {
+		if rmErr := os.RemoveAll(tmpDir); rmErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing temporary directory: %w", rmErr))
+		}
+	}()
+
+	// Starting from a copy of the real index lets Git skip re-reading files that it already knows
+	// haven't changed. A repo with nothing staged yet may not have an index at all.
+	tmpIndexPath := filepath.Join(tmpDir, "index")
+	index, err := os.ReadFile(system.ResolvePath(ctx, indexPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("reading Git index: %w", err)
+	}
+	if err == nil {
+		if err := os.WriteFile(tmpIndexPath, index, 0644); err != nil {
+			return "", fmt.Errorf("copying Git index: %w", err)
+		}
+	}
+
+	indexCtx := system.WithEnv(ctx, "GIT_INDEX_FILE="+tmpIndexPath)
+	if _, err := system.RunHostCommand(indexCtx, []string{"git", "add", "--all"}); err != nil {
+		return "", fmt.Errorf("adding work tree to temporary Git index: %w", err)
+	}
+
+	tree, err = system.RunHostCommand(indexCtx, []string{"git", "write-tree"})
+	if err != nil {
+		return "", fmt.Errorf("writing work tree snapshot: %w", err)
+	}
+
+	return tree, nil
+}
+
+// diffTrees returns a unified diff of the provided paths (relative to the repo root) between the
+// two trees, in a format that `git apply` accepts. It returns an empty string if there are no
+// paths, or no differences between them.
+func diffTrees(ctx context.Context, from string, to string, paths []string) (string, error) {
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	// NOTE: the user's Git config can change diff output in ways that `git apply` can't read back,
+	// so anything like that is set explicitly
+	args := []string{
+		"git", "diff", "--binary", "--no-color", "--no-ext-diff", "--no-textconv",
+		"--src-prefix=a/", "--dst-prefix=b/", from, to, "--",
+	}
+	for _, path := range paths {
+		args = append(args, ":(top,literal)"+path)
+	}
+
+	diff, err := system.RunHostCommand(ctx, args)
+	if err != nil {
+		return "", fmt.Errorf("diffing work tree snapshots: %w", err)
+	}
+	if diff == "" {
+		return "", nil
+	}
+
+	// Output has its final newline trimmed, but `git apply` needs it
+	return diff + "\n", nil
+}
+
+// treeDiffPaths returns the path of every file (relative to the repo root) that differs between the
+// two trees.
+func treeDiffPaths(ctx context.Context, from string, to string) ([]string, error) {
+	if from == to {
+		return nil, nil
+	}
+
+	output, err := system.RunHostCommand(ctx, []string{"git", "diff", "--name-only", "--no-renames", "-z", from, to})
+	if err != nil {
+		return nil, fmt.Errorf("diffing work tree snapshots: %w", err)
+	}
+
+	out := make([]string, 0)
+	for path := range strings.SplitSeq(output, "\x00") {
+		if path != "" {
+			out = append(out, path)
+		}
+	}
+
+	return out, nil
+}
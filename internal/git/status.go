@@ -0,0 +1,196 @@
+package igit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+// EntryKind describes what kind of change a [StatusEntry] is.
+type EntryKind string
+
+// The kinds of [StatusEntry].
+const (
+	// A tracked file whose contents (or type) changed.
+	EntryModified EntryKind = "modified"
+	// A file that was newly added to the index.
+	EntryAdded EntryKind = "added"
+	// A tracked file that was removed.
+	EntryDeleted EntryKind = "deleted"
+	// A tracked file that was moved, see [StatusEntry.OrigPath].
+	EntryRenamed EntryKind = "renamed"
+	// A file that was copied from another tracked file, see [StatusEntry.OrigPath].
+	EntryCopied EntryKind = "copied"
+	// A file with unresolved merge conflicts.
+	EntryUnmerged EntryKind = "unmerged"
+	// A file that Git is not tracking.
+	EntryUntracked EntryKind = "untracked"
+	// A file that Git is ignoring.
+	EntryIgnored EntryKind = "ignored"
+	// A submodule whose checked-out commit or contents changed, see [StatusEntry.Submodule].
+	EntrySubmodule EntryKind = "submodule"
+)
+
+// StatusEntry is a single path reported by Git status.
+type StatusEntry struct {
+	// What kind of change the entry is.
+	Kind EntryKind
+	// The two-character status code of the index & work tree, like "M." for a staged change, or
+	// ".M" for an unstaged one. Empty for untracked & ignored files.
+	Code string
+	// The submodule state, like "SC.." for a submodule whose commit changed. Empty if the path is
+	// not a submodule.
+	Submodule string
+	// The path of the file, relative to the repo root.
+	Path string
+	// The original path of a renamed or copied file, relative to the repo root.
+	OrigPath string
+}
+
+// String implements [fmt.Stringer].
+func (e StatusEntry) String() string {
+	if e.OrigPath != "" {
+		return fmt.Sprintf("%s -> %s", e.OrigPath, e.Path)
+	}
+
+	return e.Path
+}
+
+// Status holds the entries reported by Git status.
+type Status struct {
+	Entries []StatusEntry
+}
+
+// Changed returns every entry that isn't untracked or ignored, i.e. changes to files that Git
+// tracks.
+func (s Status) Changed() []StatusEntry {
+	return slices.DeleteFunc(slices.Clone(s.Entries), func(e StatusEntry) bool {
+		return e.Kind == EntryUntracked || e.Kind == EntryIgnored
+	})
+}
+
+// Untracked returns every untracked entry.
+func (s Status) Untracked() []StatusEntry {
+	return slices.DeleteFunc(slices.Clone(s.Entries), func(e StatusEntry) bool {
+		return e.Kind != EntryUntracked
+	})
+}
+
+// Paths returns the path of each of the provided entries.
+func Paths(entries []StatusEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Path)
+	}
+
+	return out
+}
+
+// Difference returns a [Status] with only the entries in s that are not also in baseline. An entry
+// that changed in any way (e.g. a staged file that now also has unstaged changes) counts as new.
+func (s Status) Difference(baseline Status) Status {
+	out := Status{Entries: make([]StatusEntry, 0)}
+	for _, e := range s.Entries {
+		if !slices.Contains(baseline.Entries, e) {
+			out.Entries = append(out.Entries, e)
+		}
+	}
+
+	return out
+}
+
+// getStatus returns the current Git status. Untracked directories are listed file by file, so that
+// new files in them are always seen.
+func getStatus(ctx context.Context) (Status, error) {
+	output, err := system.RunHostCommand(ctx, []string{
+		"git", "status", "--porcelain=v2", "-z", "--untracked-files=all",
+	})
+	if err != nil {
+		return Status{}, fmt.Errorf("getting git status output: %w", err)
+	}
+
+	return parseStatus(output)
+}
+
+// parseStatus parses the output of `git status --porcelain=v2 -z`. See the "Porcelain Format
+// Version 2" section of `git help status` for the format.
+func parseStatus(output string) (Status, error) {
+	out := Status{Entries: make([]StatusEntry, 0)}
+
+	records := strings.Split(output, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+
+		var (
+			entry  StatusEntry
+			fields []string
+		)
+		switch record[0] {
+		case '#':
+			// Headers, e.g. for branch info
+			continue
+		case '?':
+			entry = StatusEntry{Kind: EntryUntracked, Path: strings.TrimPrefix(record, "? ")}
+		case '!':
+			entry = StatusEntry{Kind: EntryIgnored, Path: strings.TrimPrefix(record, "! ")}
+		case '1':
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			fields = strings.SplitN(record, " ", 9)
+			if len(fields) < 9 {
+				return Status{}, fmt.Errorf("malformed git status entry: '%s'", record)
+			}
+			entry = StatusEntry{Kind: changeKind(fields[1]), Code: fields[1], Path: fields[8]}
+		case '2':
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>, then <origPath> as its own
+			// record
+			fields = strings.SplitN(record, " ", 10)
+			if len(fields) < 10 || i+1 >= len(records) {
+				return Status{}, fmt.Errorf("malformed git status entry: '%s'", record)
+			}
+			i++
+			entry = StatusEntry{Kind: EntryRenamed, Code: fields[1], Path: fields[9], OrigPath: records[i]}
+			if strings.HasPrefix(fields[8], "C") {
+				entry.Kind = EntryCopied
+			}
+		case 'u':
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			fields = strings.SplitN(record, " ", 11)
+			if len(fields) < 11 {
+				return Status{}, fmt.Errorf("malformed git status entry: '%s'", record)
+			}
+			entry = StatusEntry{Kind: EntryUnmerged, Code: fields[1], Path: fields[10]}
+		default:
+			return Status{}, fmt.Errorf("unknown git status entry: '%s'", record)
+		}
+
+		if len(fields) > 2 && strings.HasPrefix(fields[2], "S") {
+			entry.Submodule = fields[2]
+			if entry.Kind == EntryModified {
+				entry.Kind = EntrySubmodule
+			}
+		}
+
+		out.Entries = append(out.Entries, entry)
+	}
+
+	return out, nil
+}
+
+// changeKind returns the [EntryKind] of an ordinary changed entry, per its two-character status
+// code.
+func changeKind(code string) EntryKind {
+	switch {
+	case strings.Contains(code, "D"):
+		return EntryDeleted
+	case strings.HasPrefix(code, "A"):
+		return EntryAdded
+	default:
+		return EntryModified
+	}
+}
@@ -0,0 +1,187 @@
+package igit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepo creates a Git repo in a temporary directory, with an initial commit of the provided
+// files, and returns its path along with a function that runs Git commands in it.
+func newTestRepo(t *testing.T, files map[string]string) (string, func(args ...string)) {
+	t.Helper()
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := append([]string{
+			"git", "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"-c", "protocol.file.allow=always", "-c", "commit.gpgsign=false",
+		}, args...)
+		_, err := system.RunHostCommand(system.WithWorkDir(t.Context(), dir), cmd)
+		require.NoError(t, err)
+	}
+
+	git("init", "--quiet", "--initial-branch=main")
+	for path, contents := range files {
+		writeTestFile(t, filepath.Join(dir, path), contents)
+	}
+	git("add", "--all")
+	git("commit", "--quiet", "--allow-empty", "--message=init")
+
+	return dir, git
+}
+
+// writeTestFile writes the file at path, creating any parent directories.
+func writeTestFile(t *testing.T, path string, contents string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+}
+
+func TestGetStatus(t *testing.T) {
+	files := map[string]string{
+		"a.txt":          "a",
+		"dir/b.txt":      "b",
+		"with space.txt": "c",
+		"ünïcode.txt":    "d",
+	}
+
+	tt := []struct {
+		Name  string
+		Setup func(t *testing.T, dir string, git func(args ...string))
+		Want  []StatusEntry
+	}{
+		{
+			Name:  "clean",
+			Setup: func(*testing.T, string, func(...string)) {},
+			Want:  []StatusEntry{},
+		},
+		{
+			Name: "modified",
+			Setup: func(t *testing.T, dir string, _ func(...string)) {
+				writeTestFile(t, filepath.Join(dir, "a.txt"), "changed")
+			},
+			Want: []StatusEntry{{Kind: EntryModified, Code: ".M", Path: "a.txt"}},
+		},
+		{
+			Name: "added",
+			Setup: func(t *testing.T, dir string, git func(...string)) {
+				writeTestFile(t, filepath.Join(dir, "c.txt"), "c")
+				git("add", "c.txt")
+			},
+			Want: []StatusEntry{{Kind: EntryAdded, Code: "A.", Path: "c.txt"}},
+		},
+		{
+			Name: "deleted",
+			Setup: func(t *testing.T, dir string, _ func(...string)) {
+				require.NoError(t, os.Remove(filepath.Join(dir, "dir", "b.txt")))
+			},
+			Want: []StatusEntry{{Kind: EntryDeleted, Code: ".D", Path: "dir/b.txt"}},
+		},
+		{
+			Name: "renamed path with spaces",
+			Setup: func(_ *testing.T, _ string, git func(...string)) {
+				git("mv", "with space.txt", "moved file.txt")
+			},
+			Want: []StatusEntry{
+				{Kind: EntryRenamed, Code: "R.", Path: "moved file.txt", OrigPath: "with space.txt"},
+			},
+		},
+		{
+			Name: "non-ASCII path",
+			Setup: func(t *testing.T, dir string, _ func(...string)) {
+				writeTestFile(t, filepath.Join(dir, "ünïcode.txt"), "changed")
+			},
+			Want: []StatusEntry{{Kind: EntryModified, Code: ".M", Path: "ünïcode.txt"}},
+		},
+		{
+			Name: "untracked in untracked directory",
+			Setup: func(t *testing.T, dir string, _ func(...string)) {
+				writeTestFile(t, filepath.Join(dir, "new", "deep", "file.txt"), "new")
+			},
+			Want: []StatusEntry{{Kind: EntryUntracked, Path: "new/deep/file.txt"}},
+		},
+		{
+			Name: "submodule commit changed",
+			Setup: func(t *testing.T, dir string, git func(...string)) {
+				subDir, _ := newTestRepo(t, map[string]string{"sub.txt": "sub"})
+				git("submodule", "--quiet", "add", subDir, "sub")
+				git("commit", "--quiet", "--message=add submodule")
+
+				subGit := func(args ...string) {
+					t.Helper()
+					_, err := system.RunHostCommand(
+						system.WithWorkDir(t.Context(), filepath.Join(dir, "sub")),
+						append([]string{"git", "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...),
+					)
+					require.NoError(t, err)
+				}
+				subGit("commit", "--quiet", "--allow-empty", "--message=move submodule")
+			},
+			Want: []StatusEntry{{Kind: EntrySubmodule, Code: ".M", Submodule: "SC..", Path: "sub"}},
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			dir, git := newTestRepo(t, files)
+			s.Setup(t, dir, git)
+
+			got, err := getStatus(system.WithWorkDir(t.Context(), dir))
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got.Entries)
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	tt := []struct {
+		Name    string
+		Output  string
+		Want    []StatusEntry
+		WantErr bool
+	}{
+		{
+			Name: "copied",
+			Output: "2 C. N... 100644 100644 100644 0123 0123 C75 copy.txt\x00orig.txt\x00" +
+				"# branch.head main\x00",
+			Want: []StatusEntry{{Kind: EntryCopied, Code: "C.", Path: "copy.txt", OrigPath: "orig.txt"}},
+		},
+		{
+			Name:   "unmerged & ignored",
+			Output: "u UU N... 100644 100644 100644 100644 01 23 45 conflict.txt\x00! build/out\x00",
+			Want: []StatusEntry{
+				{Kind: EntryUnmerged, Code: "UU", Path: "conflict.txt"},
+				{Kind: EntryIgnored, Path: "build/out"},
+			},
+		},
+		{
+			Name:    "rename missing original path",
+			Output:  "2 R. N... 100644 100644 100644 0123 0123 R100 new.txt",
+			WantErr: true,
+		},
+		{
+			Name:    "truncated entry",
+			Output:  "1 .M N... 100644",
+			WantErr: true,
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, err := parseStatus(s.Output)
+			if s.WantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got.Entries)
+		})
+	}
+}
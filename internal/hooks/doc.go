@@ -0,0 +1,2 @@
+// Package hooks installs & uninstalls Git hooks that run oscar.
+package hooks
@@ -0,0 +1,169 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+const (
+	// marker is the line that identifies a hook script as one that oscar installed.
+	marker = "# Installed by oscar"
+	// chainedSuffix is added to the name of any existing hook that oscar's hook replaces. oscar's
+	// hook runs that one first, so installing oscar's hooks never loses what was already there.
+	chainedSuffix = ".pre-oscar"
+)
+
+// A hook is a Git hook that oscar installs.
+type hook struct {
+	// The Git hook's name, which is also its file name.
+	name string
+	// The Git subcommand that runs the hook, for the message about how to skip it.
+	gitCommand string
+	// The oscar command that the hook runs.
+	oscarCommand string
+}
+
+// oscarHooks lists every hook that oscar installs. Before a commit, only the staged files are
+// checked, to keep commits fast. Before a push, everything is checked -- any checks that already
+// passed against the same files are cached, so this is usually quick too.
+var oscarHooks = []hook{
+	{name: "pre-commit", gitCommand: "commit", oscarCommand: "oscar ci --staged"},
+	{name: "pre-push", gitCommand: "push", oscarCommand: "oscar ci"},
+}
+
+// Install writes oscar's Git hooks to the repo's hooks directory. Any existing hook that oscar
+// didn't install is kept & run first by oscar's hook, instead of being overwritten. Installing again
+// just updates oscar's hooks.
+func Install(ctx context.Context) error {
+	dir, err := hooksDir(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating Git hooks directory: %w", err)
+	}
+
+	for _, h := range oscarHooks {
+		path := filepath.Join(dir, h.name)
+		chainedPath := path + chainedSuffix
+
+		existing, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("reading existing '%s' hook: %w", h.name, err)
+		}
+
+		if err == nil && !isOscarHook(existing) {
+			if _, err := os.Stat(chainedPath); err == nil {
+				return fmt.Errorf(
+					"cannot install '%s' hook, since both '%s' and '%s' already exist -- remove one of them first",
+					h.name, path, chainedPath,
+				)
+			}
+			if err := os.Rename(path, chainedPath); err != nil {
+				return fmt.Errorf("moving existing '%s' hook: %w", h.name, err)
+			}
+			iprint.Infof("Moved existing '%s' hook to '%s', which oscar's hook will run first\n", h.name, chainedPath)
+		}
+
+		if err := os.WriteFile(path, []byte(h.script()), 0755); err != nil {
+			return fmt.Errorf("writing '%s' hook: %w", h.name, err)
+		}
+		// NOTE: WriteFile only sets the mode on new files
+		if err := os.Chmod(path, 0755); err != nil {
+			return fmt.Errorf("making '%s' hook executable: %w", h.name, err)
+		}
+		iprint.Goodf("Installed '%s' hook at '%s'\n", h.name, path)
+	}
+
+	return nil
+}
+
+// Uninstall removes oscar's Git hooks from the repo's hooks directory, and puts back any hooks that
+// they replaced. Hooks that oscar didn't install are left alone.
+func Uninstall(ctx context.Context) error {
+	dir, err := hooksDir(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range oscarHooks {
+		path := filepath.Join(dir, h.name)
+		chainedPath := path + chainedSuffix
+
+		existing, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading existing '%s' hook: %w", h.name, err)
+		}
+		if !isOscarHook(existing) {
+			iprint.Warnf("Not removing '%s' hook at '%s', since oscar didn't install it\n", h.name, path)
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing '%s' hook: %w", h.name, err)
+		}
+		iprint.Goodf("Removed '%s' hook\n", h.name)
+
+		if _, err := os.Stat(chainedPath); err == nil {
+			if err := os.Rename(chainedPath, path); err != nil {
+				return fmt.Errorf("restoring previous '%s' hook: %w", h.name, err)
+			}
+			iprint.Infof("Restored the '%s' hook that was there before oscar's\n", h.name)
+		}
+	}
+
+	return nil
+}
+
+// hooksDir returns the directory that Git runs hooks from, which respects any `core.hooksPath`
+// setting.
+func hooksDir(ctx context.Context) (string, error) {
+	dir, err := system.RunHostCommand(ctx, []string{"git", "rev-parse", "--git-path", "hooks"})
+	if err != nil {
+		return "", fmt.Errorf("finding Git hooks directory: %w", err)
+	}
+
+	return system.ResolvePath(ctx, dir), nil
+}
+
+// isOscarHook reports whether the hook script contents are from a hook that oscar installed.
+func isOscarHook(contents []byte) bool {
+	return bytes.Contains(contents, []byte(marker))
+}
+
+// script returns the contents of the hook's script.
+func (h hook) script() string {
+	return fmt.Sprintf(`#!/bin/sh
+%s -- remove it with 'oscar hooks uninstall'.
+#
+# Any '%s' hook that was here before this one was moved to '%s%s', and runs first.
+
+chained="$(dirname "$0")/%s%s"
+if [ -x "${chained}" ]; then
+	"${chained}" "$@" || exit $?
+fi
+
+if ! command -v oscar > /dev/null 2>&1; then
+	echo "oscar was not found on your PATH -- install it, or skip this hook with 'git %s --no-verify'" >&2
+	exit 1
+fi
+
+exec %s
+`,
+		marker,
+		h.name, h.name, chainedSuffix,
+		h.name, chainedSuffix,
+		h.gitCommand,
+		h.oscarCommand,
+	)
+}
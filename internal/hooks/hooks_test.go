@@ -0,0 +1,83 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepo creates an empty Git repo in a temporary directory, and returns its path along with
+// the path of its hooks directory.
+func newTestRepo(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	_, err := system.RunHostCommand(system.WithWorkDir(t.Context(), dir), []string{"git", "init", "--quiet"})
+	require.NoError(t, err)
+
+	return dir, filepath.Join(dir, ".git", "hooks")
+}
+
+func TestInstallUninstall(t *testing.T) {
+	dir, hooksDir := newTestRepo(t)
+	ctx := system.WithWorkDir(t.Context(), dir)
+
+	logPath := filepath.Join(dir, "hooks.log")
+	existingHook := "#!/bin/sh\necho \"existing $*\" >> " + logPath + "\n"
+	require.NoError(t, os.MkdirAll(hooksDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte(existingHook), 0755))
+
+	require.NoError(t, Install(ctx))
+	// Installing again only updates oscar's hooks
+	require.NoError(t, Install(ctx))
+
+	chained, err := os.ReadFile(filepath.Join(hooksDir, "pre-commit"+chainedSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, existingHook, string(chained))
+	for _, name := range []string{"pre-commit", "pre-push"} {
+		info, err := os.Stat(filepath.Join(hooksDir, name))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&0100, "hook '%s' should be executable", name)
+	}
+
+	t.Run("runs existing hook first", func(t *testing.T) {
+		binDir := t.TempDir()
+		fakeOscar := "#!/bin/sh\necho \"oscar $*\" >> " + logPath + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "oscar"), []byte(fakeOscar), 0755))
+		t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		_, err := system.RunHostCommand(ctx, []string{filepath.Join(hooksDir, "pre-commit"), "arg"})
+		require.NoError(t, err)
+
+		log, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		assert.Equal(t, "existing arg\noscar ci --staged\n", string(log))
+	})
+
+	require.NoError(t, Uninstall(ctx))
+
+	restored, err := os.ReadFile(filepath.Join(hooksDir, "pre-commit"))
+	require.NoError(t, err)
+	assert.Equal(t, existingHook, string(restored))
+	assert.NoFileExists(t, filepath.Join(hooksDir, "pre-commit"+chainedSuffix))
+	assert.NoFileExists(t, filepath.Join(hooksDir, "pre-push"))
+
+	// Hooks that oscar didn't install are never removed
+	require.NoError(t, Uninstall(ctx))
+	assert.FileExists(t, filepath.Join(hooksDir, "pre-commit"))
+}
+
+func TestInstallWontClobber(t *testing.T) {
+	dir, hooksDir := newTestRepo(t)
+
+	require.NoError(t, os.MkdirAll(hooksDir, 0755))
+	for _, name := range []string{"pre-commit", "pre-commit" + chainedSuffix} {
+		require.NoError(t, os.WriteFile(filepath.Join(hooksDir, name), []byte("#!/bin/sh\n"), 0755))
+	}
+
+	assert.Error(t, Install(system.WithWorkDir(t.Context(), dir)))
+}
@@ -1,6 +1,7 @@
 package oscarcfg
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,19 +9,22 @@ import (
 	"buf.build/go/protovalidate"
 	"github.com/opensourcecorp/oscar/internal/consts"
 	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	igit "github.com/opensourcecorp/oscar/internal/git"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 	"go.yaml.in/yaml/v4"
 	"golang.org/x/mod/semver"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// DefaultBaseBranch is the base branch used when the oscar config file doesn't set one.
+const DefaultBaseBranch = "main"
+
 // Get returns a populated [Config] based on the oscar config file location. If `path` is not
 // provided, it will default to looking in the calling directory.
 func Get(pathOverride ...string) (*oscarcfgpbv1.Config, error) {
 	path := consts.DefaultOscarCfgFileName
 
-	// Handle the override so we can test this function, and use it in other ways (like checking the
-	// main branch's version data)
+	// Handle the override so we can test this function
 	if len(pathOverride) > 0 {
 		path = pathOverride[0]
 	}
@@ -31,26 +35,62 @@ func Get(pathOverride ...string) (*oscarcfgpbv1.Config, error) {
 	}
 	iprint.Debugf("data read from oscar config file:\n%s\n", string(yamlData))
 
+	cfg, err := Parse(yamlData)
+	if err != nil {
+		return nil, fmt.Errorf("oscar config file '%s': %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// GetAtRef returns a populated [Config] based on the oscar config file as of the provided Git ref,
+// e.g. to compare against the base branch's config.
+func GetAtRef(ctx context.Context, ref string) (*oscarcfgpbv1.Config, error) {
+	yamlData, err := igit.ShowFile(ctx, ref, consts.DefaultOscarCfgFileName)
+	if err != nil {
+		return nil, fmt.Errorf("reading oscar config file: %w", err)
+	}
+
+	cfg, err := Parse([]byte(yamlData))
+	if err != nil {
+		return nil, fmt.Errorf("oscar config file on '%s': %w", ref, err)
+	}
+
+	return cfg, nil
+}
+
+// BaseBranch returns the name of the base branch set in the provided [Config], or
+// [DefaultBaseBranch] if there isn't one.
+func BaseBranch(cfg *oscarcfgpbv1.Config) string {
+	if cfg.GetBaseBranch() == "" {
+		return DefaultBaseBranch
+	}
+
+	return cfg.GetBaseBranch()
+}
+
+// Parse returns a populated & validated [Config] from raw oscar config file YAML data.
+func Parse(yamlData []byte) (*oscarcfgpbv1.Config, error) {
 	jsonSweepMap := make(map[string]any)
 	if err := yaml.Unmarshal(yamlData, jsonSweepMap); err != nil {
-		panic(err)
+		return nil, fmt.Errorf("parsing YAML: %w", err)
 	}
 	iprint.Debugf("YAML data unmarshalled to map: %+v\n", jsonSweepMap)
 
 	jsonData, err := json.Marshal(jsonSweepMap)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("converting YAML to JSON: %w", err)
 	}
 	iprint.Debugf("map data as JSON string: %s\n", string(jsonData))
 
 	var cfg = &oscarcfgpbv1.Config{}
 	if err := protojson.Unmarshal(jsonData, cfg); err != nil {
-		return nil, fmt.Errorf("unmarshalling oscar config file '%s': %w", path, err)
+		return nil, fmt.Errorf("unmarshalling: %w", err)
 	}
 	iprint.Debugf("proto message: %+v\n", cfg)
 
 	if err := protovalidate.Validate(cfg); err != nil {
-		return nil, fmt.Errorf("validating oscar config file '%s': %w", path, err)
+		return nil, fmt.Errorf("validating: %w", err)
 	}
 
 	return cfg, nil
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 const testConfigFilePath = "test.oscar.yaml"
@@ -26,4 +27,110 @@ func TestGet(t *testing.T) {
 
 		assert.Equal(t, wantBuildSources, gotBuildSources)
 	})
+
+	t.Run("projects", func(t *testing.T) {
+		assert.Equal(t, []string{"infra/prod"}, cfg.GetProjects())
+	})
+
+	t.Run("custom tasks", func(t *testing.T) {
+		require.Len(t, cfg.GetCustomTasks(), 1)
+		task := cfg.GetCustomTasks()[0]
+		assert.Equal(t, "Lint (sqlfluff)", task.GetName())
+		assert.Equal(t, []string{"sqlfluff", "lint"}, task.GetCommand())
+		assert.Equal(t, map[string]string{"SQLFLUFF_DIALECT": "postgres"}, task.GetEnv())
+	})
+}
+
+func TestParseInvalid(t *testing.T) {
+	tt := []struct {
+		Name string
+		Data string
+	}{
+		{Name: "invalid YAML", Data: "version: [\n"},
+		{Name: "not a map", Data: "- version\n"},
+		{Name: "unknown field", Data: "version: \"1.0.0\"\nnope: true\n"},
+		{Name: "fails validation", Data: "version: \"not-a-version\"\n"},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				_, err := Parse([]byte(s.Data))
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestParseInvalidCustomTasks(t *testing.T) {
+	tt := []struct {
+		Name string
+		Task string
+	}{
+		{Name: "no name", Task: `{command: ["make"]}`},
+		{Name: "no command", Task: `{name: "Lint"}`},
+		{Name: "name too long", Task: `{name: "This name is far too long to fit in the output", command: ["make"]}`},
+		{Name: "invalid env var", Task: `{name: "Lint", command: ["make"], env: {"NOT VALID": "x"}}`},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			_, err := Parse([]byte("version: \"1.0.0\"\ncustom_tasks: [" + s.Task + "]\n"))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseInvalidContainerImage(t *testing.T) {
+	tt := []struct {
+		Name  string
+		Image string
+	}{
+		{Name: "unknown builder", Image: `{registry: "ghcr.io", namespace: "a", name: "b", builder: "kaniko"}`},
+		{Name: "compose without file", Image: `{registry: "ghcr.io", namespace: "a", name: "b", compose: {service: "b"}}`},
+		{Name: "unknown auth provider", Image: `{registry: "ghcr.io", namespace: "a", name: "b", auth: {provider: "ecr"}}`},
+		{Name: "unknown tag kind", Image: `{registry: "ghcr.io", namespace: "a", name: "b", tags: {release: ["stable"]}}`},
+		{Name: "duplicate tag kind", Image: `{registry: "ghcr.io", namespace: "a", name: "b", tags: {other: ["commit", "commit"]}}`},
+		{Name: "unpinned emulator image", Image: `{registry: "ghcr.io", namespace: "a", name: "b", emulator_image: "docker.io/tonistiigi/binfmt:latest"}`},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			_, err := Parse([]byte("version: \"1.0.0\"\ndeliverables:\n  container_image: " + s.Image + "\n"))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseDuplicateProjects(t *testing.T) {
+	_, err := Parse([]byte("version: \"1.0.0\"\nprojects: [\"a\", \"a\"]\n"))
+	assert.Error(t, err)
+}
+
+func TestBaseBranch(t *testing.T) {
+	cfg, err := Parse([]byte("version: \"1.0.0\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBaseBranch, BaseBranch(cfg))
+
+	cfg, err = Parse([]byte("version: \"1.0.0\"\nbase_branch: \"develop\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "develop", BaseBranch(cfg))
+
+	_, err = Parse([]byte("version: \"1.0.0\"\nbase_branch: \"not a branch\"\n"))
+	assert.Error(t, err)
+}
+
+func TestMarshal(t *testing.T) {
+	cfg, err := Get(testConfigFilePath)
+	require.NoError(t, err)
+
+	yamlData, err := Marshal(cfg)
+	require.NoError(t, err)
+
+	t.Logf("marshalled cfg:\n%s", string(yamlData))
+
+	roundTripped, err := Parse(yamlData)
+	require.NoError(t, err)
+
+	assert.True(t, proto.Equal(cfg, roundTripped))
 }
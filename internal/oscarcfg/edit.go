@@ -0,0 +1,124 @@
+package oscarcfg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// SetVersion returns the provided oscar config file YAML data, with its version set to newVersion.
+// Unlike a [Parse] & [Marshal] round trip, only the version's value itself is changed, so any
+// comments & formatting in the file are kept as-is. The value keeps its original quoting style.
+func SetVersion(yamlData []byte, newVersion string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(yamlData, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshalling: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("config file is not a YAML mapping")
+	}
+
+	var valueNode *yaml.Node
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "version" {
+			valueNode = root.Content[i+1]
+			break
+		}
+	}
+	if valueNode == nil || valueNode.Kind != yaml.ScalarNode {
+		return nil, errors.New("config file has no 'version' field to update")
+	}
+
+	start, err := offsetOf(yamlData, valueNode.Line, valueNode.Column)
+	if err != nil {
+		return nil, err
+	}
+	end, err := scalarEnd(yamlData, start, valueNode)
+	if err != nil {
+		return nil, err
+	}
+
+	var replacement string
+	switch valueNode.Style {
+	case yaml.DoubleQuotedStyle:
+		replacement = strconv.Quote(newVersion)
+	case yaml.SingleQuotedStyle:
+		replacement = "'" + newVersion + "'"
+	default:
+		replacement = newVersion
+	}
+
+	out := bytes.Join([][]byte{yamlData[:start], []byte(replacement), yamlData[end:]}, nil)
+
+	// Make sure the result is still a valid config, with the version that was asked for
+	cfg, err := Parse(out)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.GetVersion() != newVersion {
+		return nil, fmt.Errorf(
+			"internal error: version is '%s' after update, but expected '%s'",
+			cfg.GetVersion(), newVersion,
+		)
+	}
+
+	return out, nil
+}
+
+// offsetOf returns the byte offset in data of the provided 1-indexed line & (character) column, as
+// reported by a [yaml.Node].
+func offsetOf(data []byte, line int, column int) (int, error) {
+	offset := 0
+	for range line - 1 {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i == -1 {
+			return 0, fmt.Errorf("internal error: line %d is past the end of the file", line)
+		}
+		offset += i + 1
+	}
+
+	for range column - 1 {
+		if offset >= len(data) || data[offset] == '\n' {
+			return 0, fmt.Errorf("internal error: column %d is past the end of line %d", column, line)
+		}
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+
+	return offset, nil
+}
+
+// scalarEnd returns the byte offset in data just past the end of the scalar node that starts at
+// start, including any quotes around it.
+func scalarEnd(data []byte, start int, node *yaml.Node) (int, error) {
+	switch node.Style {
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		quote := data[start]
+		for i := start + 1; i < len(data); i++ {
+			switch {
+			case node.Style == yaml.DoubleQuotedStyle && data[i] == '\\':
+				// Skip over whatever is escaped
+				i++
+			case node.Style == yaml.SingleQuotedStyle && data[i] == '\'' && i+1 < len(data) && data[i+1] == '\'':
+				// Single-quoted strings escape quotes by doubling them
+				i++
+			case data[i] == quote:
+				return i + 1, nil
+			}
+		}
+		return 0, errors.New("internal error: unterminated quoted version value")
+	case 0:
+		end := start + len(node.Value)
+		if end > len(data) || string(data[start:end]) != node.Value {
+			return 0, errors.New("internal error: could not find version value in config file")
+		}
+		return end, nil
+	default:
+		return 0, errors.New("version value must be a plain or quoted string, not a block scalar")
+	}
+}
@@ -0,0 +1,50 @@
+package oscarcfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVersion(t *testing.T) {
+	tt := []struct {
+		Name  string
+		Input string
+		Want  string
+	}{
+		{
+			Name:  "double-quoted, with comments",
+			Input: "---\n# Our version\nversion: \"1.0.0\" # bumped by CI\nprojects:\n  - \"a\"\n",
+			Want:  "---\n# Our version\nversion: \"1.1.0\" # bumped by CI\nprojects:\n  - \"a\"\n",
+		},
+		{
+			Name:  "single-quoted",
+			Input: "version:   '1.0.0'\n",
+			Want:  "version:   '1.1.0'\n",
+		},
+		{
+			Name:  "plain, not first",
+			Input: "projects: [\"a\"]\nversion: 1.0.0\n",
+			Want:  "projects: [\"a\"]\nversion: 1.1.0\n",
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, err := SetVersion([]byte(s.Input), "1.1.0")
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, string(got))
+		})
+	}
+
+	t.Run("no version field", func(t *testing.T) {
+		_, err := SetVersion([]byte("projects: [\"a\"]\n"), "1.1.0")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid new version", func(t *testing.T) {
+		_, err := SetVersion([]byte("version: \"1.0.0\"\n"), "not-a-version")
+		assert.Error(t, err)
+	})
+}
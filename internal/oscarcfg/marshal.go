@@ -0,0 +1,106 @@
+package oscarcfg
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"buf.build/go/protovalidate"
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"go.yaml.in/yaml/v4"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Marshal validates the provided [Config], and renders it as YAML for an oscar config file. Fields
+// are written in the order they are declared in the proto definitions, and any unset fields are
+// left out. Strings are always double-quoted, to match the YAML linter config that oscar uses.
+func Marshal(cfg *oscarcfgpbv1.Config) ([]byte, error) {
+	if err := protovalidate.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("validating: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(messageToNode(cfg.ProtoReflect())); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("closing YAML encoder: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// messageToNode converts a proto message into a YAML mapping node.
+func messageToNode(msg protoreflect.Message) *yaml.Node {
+	out := &yaml.Node{Kind: yaml.MappingNode}
+
+	fields := msg.Descriptor().Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		if !msg.Has(field) {
+			continue
+		}
+
+		var valueNode *yaml.Node
+		switch {
+		case field.IsMap():
+			// Keys are sorted, so that the output is always the same
+			valueNode = &yaml.Node{Kind: yaml.MappingNode}
+			entries := msg.Get(field).Map()
+			keys := make([]protoreflect.MapKey, 0, entries.Len())
+			entries.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+				keys = append(keys, key)
+				return true
+			})
+			slices.SortFunc(keys, func(a, b protoreflect.MapKey) int {
+				return strings.Compare(a.String(), b.String())
+			})
+			for _, key := range keys {
+				valueNode.Content = append(
+					valueNode.Content,
+					valueToNode(field.MapKey(), key.Value()),
+					valueToNode(field.MapValue(), entries.Get(key)),
+				)
+			}
+		case field.IsList():
+			valueNode = &yaml.Node{Kind: yaml.SequenceNode}
+			list := msg.Get(field).List()
+			for j := range list.Len() {
+				valueNode.Content = append(valueNode.Content, valueToNode(field, list.Get(j)))
+			}
+		default:
+			valueNode = valueToNode(field, msg.Get(field))
+		}
+
+		out.Content = append(
+			out.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: field.TextName()},
+			valueNode,
+		)
+	}
+
+	return out
+}
+
+// valueToNode converts a single (non-list) proto field value into a YAML node.
+func valueToNode(field protoreflect.FieldDescriptor, value protoreflect.Value) *yaml.Node {
+	switch field.Kind() {
+	case protoreflect.MessageKind:
+		return messageToNode(value.Message())
+	case protoreflect.StringKind:
+		return &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: value.String()}
+	case protoreflect.EnumKind:
+		enumValue := field.Enum().Values().ByNumber(value.Enum())
+		return &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: string(enumValue.Name())}
+	case protoreflect.BoolKind:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value.Bool())}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value.String()}
+	}
+}
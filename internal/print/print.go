@@ -77,5 +77,11 @@ func Goodf(format string, args ...any) {
 // RunDurationString returns a calculated duration used to indicate how long a particular Task (or
 // set of Tasks) took to run.
 func RunDurationString(t time.Time) string {
-	return fmt.Sprintf("t: %s", time.Since(t).Round(time.Second/1000).String())
+	return DurationString(time.Since(t))
+}
+
+// DurationString is like [RunDurationString], but for an already-known duration, e.g. for a Task
+// that finished some time before its results are printed.
+func DurationString(d time.Duration) string {
+	return fmt.Sprintf("t: %s", d.Round(time.Second/1000).String())
 }
@@ -0,0 +1,3 @@
+// Package report writes machine-readable reports of oscar runs, for use by CI systems, dashboards,
+// code-scanning tools, etc.
+package report
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"encoding/json"
+	"time"
+
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+)
+
+// jsonReport is the top-level structure of oscar's own JSON report format.
+type jsonReport struct {
+	Runs []jsonRun `json:"runs"`
+}
+
+// jsonRun holds the results of a single [taskutil.Run].
+type jsonRun struct {
+	Type            string     `json:"type"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         time.Time  `json:"end_time"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	Tasks           []jsonTask `json:"tasks"`
+}
+
+// jsonTask holds the results of a single Task.
+type jsonTask struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Project         string    `json:"project"`
+	Group           string    `json:"group"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Status          string    `json:"status"`
+	Cached          bool      `json:"cached,omitempty"`
+	ExitCode        *int      `json:"exit_code,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	Output          string    `json:"output"`
+	LogFile         string    `json:"log_file,omitempty"`
+	ChangedFiles    []string  `json:"changed_files,omitempty"`
+	CreatedFiles    []string  `json:"created_files,omitempty"`
+}
+
+// renderJSON renders records in oscar's own JSON report format.
+func renderJSON(records []taskutil.RunRecord) ([]byte, error) {
+	report := jsonReport{Runs: make([]jsonRun, 0, len(records))}
+
+	for _, record := range records {
+		run := jsonRun{
+			Type:            record.Type,
+			StartTime:       record.StartTime,
+			EndTime:         record.EndTime,
+			DurationSeconds: record.EndTime.Sub(record.StartTime).Seconds(),
+			Tasks:           make([]jsonTask, 0, len(record.Results)),
+		}
+
+		for _, result := range record.Results {
+			task := jsonTask{
+				ID:              result.ID(),
+				Name:            result.Task.InfoText(),
+				Project:         result.Project,
+				Group:           result.Lang,
+				StartTime:       result.StartTime,
+				EndTime:         result.EndTime,
+				DurationSeconds: result.Duration().Seconds(),
+				Status:          string(result.Status),
+				Cached:          result.Cached,
+				Output:          result.Output,
+				LogFile:         result.LogFile,
+			}
+
+			if result.Err != nil {
+				task.Error = result.Err.Error()
+			}
+			if code, ok := exitCode(result); ok {
+				task.ExitCode = &code
+			}
+
+			changes := flaggedFiles(result)
+			task.ChangedFiles = igit.Paths(changes.Changed())
+			task.CreatedFiles = igit.Paths(changes.Untracked())
+
+			run.Tasks = append(run.Tasks, task)
+		}
+
+		report.Runs = append(report.Runs, run)
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds every Task for a single language/tooling group in a run.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single Task.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure describes why a Task failed.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped marks a Task that was not run.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// renderJUnit renders records as JUnit XML, with one test suite per run & language/tooling group,
+// and one test case per Task.
+func renderJUnit(records []taskutil.RunRecord) ([]byte, error) {
+	report := junitTestSuites{Name: "oscar"}
+	var totalDuration time.Duration
+
+	for _, record := range records {
+		totalDuration += record.EndTime.Sub(record.StartTime)
+
+		suiteIndexes := make(map[string]int)
+		suiteDurations := make(map[int]time.Duration)
+		for _, result := range record.Results {
+			group := result.Lang
+			if result.Project != "" && result.Project != taskutil.RootProject {
+				group = fmt.Sprintf("%s :: %s", result.Project, result.Lang)
+			}
+			suiteName := fmt.Sprintf("%s :: %s", record.Type, group)
+			i, found := suiteIndexes[suiteName]
+			if !found {
+				i = len(report.Suites)
+				suiteIndexes[suiteName] = i
+				report.Suites = append(report.Suites, junitTestSuite{
+					Name:      suiteName,
+					Timestamp: result.StartTime.Format(time.RFC3339),
+				})
+			}
+			suite := &report.Suites[i]
+
+			testCase := junitTestCase{
+				Name:      result.Task.InfoText(),
+				ClassName: fmt.Sprintf("%s.%s", record.Type, strings.ReplaceAll(group, " :: ", ".")),
+				Time:      junitSeconds(result.Duration()),
+				SystemOut: result.Output,
+			}
+
+			if result.Status == taskutil.StatusFailed || result.Status == taskutil.StatusTimedOut {
+				testCase.Failure = &junitFailure{
+					Message: fmt.Sprintf("%s %s", result.ID(), result.Status),
+					Type:    string(result.Status),
+				}
+				if result.Err != nil {
+					testCase.Failure.Text = result.Err.Error()
+				}
+				suite.Failures++
+				report.Failures++
+			}
+
+			if result.Status == taskutil.StatusSkipped {
+				testCase.Skipped = &junitSkipped{Message: "skipped via task filters"}
+				suite.Skipped++
+				report.Skipped++
+			}
+
+			suite.Tests++
+			report.Tests++
+			suite.TestCases = append(suite.TestCases, testCase)
+
+			suiteDurations[i] += result.Duration()
+			suite.Time = junitSeconds(suiteDurations[i])
+		}
+	}
+	report.Time = junitSeconds(totalDuration)
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// junitSeconds formats a duration as the decimal seconds that JUnit XML expects.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+)
+
+// Format is a supported report file format.
+type Format string
+
+const (
+	// FormatJSON is oscar's own JSON report format.
+	FormatJSON Format = "json"
+	// FormatJUnit is JUnit XML, where each Task is a test case.
+	FormatJUnit Format = "junit"
+	// FormatSARIF is SARIF, made up of any findings that could be parsed from each Task's output.
+	FormatSARIF Format = "sarif"
+)
+
+// formats holds every supported [Format], and the function that renders it.
+var formats = map[Format]func([]taskutil.RunRecord) ([]byte, error){
+	FormatJSON:  renderJSON,
+	FormatJUnit: renderJUnit,
+	FormatSARIF: renderSARIF,
+}
+
+// A Spec describes a single report to write.
+type Spec struct {
+	Format Format
+	Path   string
+}
+
+// ParseSpec parses a report spec string of the form "FORMAT=PATH", e.g. "junit=build/report.xml".
+func ParseSpec(s string) (Spec, error) {
+	format, path, found := strings.Cut(s, "=")
+	if !found || path == "" {
+		return Spec{}, fmt.Errorf("report '%s' must be of the form FORMAT=PATH", s)
+	}
+
+	spec := Spec{Format: Format(strings.ToLower(format)), Path: path}
+	if _, ok := formats[spec.Format]; !ok {
+		supported := make([]string, 0, len(formats))
+		for f := range formats {
+			supported = append(supported, string(f))
+		}
+		slices.Sort(supported)
+
+		return Spec{}, fmt.Errorf("unsupported report format '%s' (supported: %v)", format, supported)
+	}
+
+	return spec, nil
+}
+
+// Write renders the provided records in the format described by the [Spec], and writes them to the
+// Spec's path.
+func Write(spec Spec, records []taskutil.RunRecord) error {
+	data, err := formats[spec.Format](records)
+	if err != nil {
+		return fmt.Errorf("rendering %s report: %w", spec.Format, err)
+	}
+
+	if dir := filepath.Dir(spec.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating report directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(spec.Path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s report: %w", spec.Format, err)
+	}
+
+	iprint.Infof("Wrote %s report to '%s'\n", spec.Format, spec.Path)
+
+	return nil
+}
+
+// exitCode returns the exit code of the command that failed a Task, if there was one.
+func exitCode(result taskutil.Result) (int, bool) {
+	var exitErr *exec.ExitError
+	if errors.As(result.Err, &exitErr) {
+		return exitErr.ExitCode(), true
+	}
+
+	return 0, false
+}
+
+// flaggedFiles returns the files that the Git-diff check flagged for a Task, if any.
+func flaggedFiles(result taskutil.Result) igit.Changes {
+	var changesErr *igit.ChangesError
+	if errors.As(result.Err, &changesErr) {
+		return changesErr.Changes
+	}
+
+	return igit.Changes{}
+}
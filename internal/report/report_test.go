@@ -0,0 +1,121 @@
+package report
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTask is a no-op [taskutil.Tasker].
+type fakeTask struct {
+	taskutil.Tool
+	name string
+}
+
+func (t fakeTask) InfoText() string             { return t.name }
+func (t fakeTask) Exec(_ context.Context) error { return nil }
+func (t fakeTask) Post(_ context.Context) error { return nil }
+
+func TestParseSpec(t *testing.T) {
+	tt := []struct {
+		Name    string
+		Input   string
+		Want    Spec
+		WantErr bool
+	}{
+		{Name: "json", Input: "json=out/report.json", Want: Spec{Format: FormatJSON, Path: "out/report.json"}},
+		{Name: "case-insensitive format", Input: "JUnit=report.xml", Want: Spec{Format: FormatJUnit, Path: "report.xml"}},
+		{Name: "missing path", Input: "sarif=", WantErr: true},
+		{Name: "missing format", Input: "report.json", WantErr: true},
+		{Name: "unknown format", Input: "html=report.html", WantErr: true},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, err := ParseSpec(s.Input)
+			if s.WantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+}
+
+func TestParseFindings(t *testing.T) {
+	realFile := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(realFile, []byte("package main\n"), 0644))
+
+	output := "# some/pkg\n" +
+		realFile + ":12:2: printf: non-constant format string\n" +
+		realFile + ":3: missing docstring\n" +
+		"running '[go vet ./...]': exit status 1\n" +
+		"does/not/exist.go:1:1: not a real file\n"
+
+	got := parseFindings(output, ".")
+	want := []finding{
+		{Path: filepath.ToSlash(realFile), Line: 12, Column: 2, Message: "printf: non-constant format string"},
+		{Path: filepath.ToSlash(realFile), Line: 3, Message: "missing docstring"},
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestRenderJUnit(t *testing.T) {
+	start := time.Now()
+	records := []taskutil.RunRecord{{
+		Type:      "CI",
+		StartTime: start,
+		EndTime:   start.Add(3 * time.Second),
+		Results: []taskutil.Result{
+			{
+				Lang:      "Go",
+				Task:      fakeTask{name: "Build"},
+				StartTime: start,
+				EndTime:   start.Add(time.Second),
+				Status:    taskutil.StatusPassed,
+			},
+			{
+				Lang:      "Go",
+				Task:      fakeTask{name: "Format"},
+				StartTime: start,
+				EndTime:   start.Add(2 * time.Second),
+				Status:    taskutil.StatusFailed,
+				Err: errors.Join(
+					errors.New("formatting failed"),
+					&igit.ChangesError{Changes: igit.Changes{Status: igit.Status{
+						Entries: []igit.StatusEntry{{Kind: igit.EntryModified, Code: ".M", Path: "main.go"}},
+					}}},
+				),
+			},
+		},
+	}}
+
+	data, err := renderJUnit(records)
+	require.NoError(t, err)
+
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &got))
+
+	assert.Equal(t, 2, got.Tests)
+	assert.Equal(t, 1, got.Failures)
+	require.Len(t, got.Suites, 1)
+	assert.Equal(t, "CI :: Go", got.Suites[0].Name)
+	assert.Equal(t, "3.000", got.Suites[0].Time)
+	assert.Nil(t, got.Suites[0].TestCases[0].Failure)
+	require.NotNil(t, got.Suites[0].TestCases[1].Failure)
+	assert.Contains(t, got.Suites[0].TestCases[1].Failure.Text, "main.go")
+
+	assert.Equal(t, []string{"main.go"}, igit.Paths(flaggedFiles(records[0].Results[1]).Changed()))
+}
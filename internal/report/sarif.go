@@ -0,0 +1,187 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	oscarInfoURI = "https://github.com/opensourcecorp/oscar"
+)
+
+var (
+	// findingRegex matches the "path:line[:column]: message" format that most linters can emit,
+	// e.g. `main.go:12:2: printf: non-constant format string`. Some tools (like markdownlint) leave
+	// off the trailing colon, so it's optional.
+	findingRegex = regexp.MustCompile(`^(?:vet: )?([^\s:][^:]*):(\d+)(?::(\d+))?:?\s+(.+)$`)
+	// ansiRegex matches ANSI escape codes, which some tools print even when not writing to a
+	// terminal.
+	ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// sarifLog is the top-level structure of a SARIF file.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun holds the findings from a single Task.
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+}
+
+type sarifResult struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// A finding is a single issue that a tool reported about a file.
+type finding struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// renderSARIF renders records as SARIF, with one SARIF run per Task that had any findings. Findings
+// come from parsing each Task's output, along with any files flagged by the Git-diff check.
+func renderSARIF(records []taskutil.RunRecord) ([]byte, error) {
+	report := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    make([]sarifRun, 0),
+	}
+
+	for _, record := range records {
+		for _, result := range record.Results {
+			results := make([]sarifResult, 0)
+
+			for _, f := range parseFindings(result.Output, result.Project) {
+				results = append(results, sarifResult{
+					Level:   "error",
+					Message: sarifMessage{Text: f.Message},
+					Locations: []sarifLocation{{
+						PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: f.Path},
+							Region:           &sarifRegion{StartLine: f.Line, StartColumn: f.Column},
+						},
+					}},
+				})
+			}
+
+			changes := flaggedFiles(result)
+			for _, path := range igit.Paths(changes.Entries) {
+				results = append(results, sarifResult{
+					Level: "error",
+					Message: sarifMessage{
+						Text: fmt.Sprintf("File was changed by '%s' -- run it locally & commit the result", result.ID()),
+					},
+					Locations: []sarifLocation{{
+						PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(path)},
+						},
+					}},
+				})
+			}
+
+			if len(results) == 0 {
+				continue
+			}
+
+			report.Runs = append(report.Runs, sarifRun{
+				Tool: sarifTool{Driver: sarifDriver{
+					Name:           fmt.Sprintf("oscar :: %s :: %s", record.Type, result.ID()),
+					InformationURI: oscarInfoURI,
+				}},
+				Results: results,
+			})
+		}
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// parseFindings parses any "path:line[:column]: message" lines out of a tool's output. Relative
+// paths are taken to be relative to the root of the project that the tool ran for. Lines whose paths
+// don't point to a real file are ignored, since they're likely not findings at all.
+func parseFindings(output string, project string) []finding {
+	out := make([]finding, 0)
+
+	for _, line := range strings.Split(ansiRegex.ReplaceAllString(output, ""), "\n") {
+		groups := findingRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if groups == nil {
+			continue
+		}
+
+		path := filepath.Clean(groups[1])
+		if !filepath.IsAbs(path) && project != "" {
+			path = filepath.Join(project, path)
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+
+		lineNum, err := strconv.Atoi(groups[2])
+		if err != nil {
+			continue
+		}
+
+		var column int
+		if groups[3] != "" {
+			column, _ = strconv.Atoi(groups[3])
+		}
+
+		out = append(out, finding{
+			Path:    filepath.ToSlash(path),
+			Line:    lineNum,
+			Column:  column,
+			Message: groups[4],
+		})
+	}
+
+	return out
+}
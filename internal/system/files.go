@@ -0,0 +1,207 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+)
+
+// fileTypeExtensions maps each file type that oscar knows about to the file extensions for that
+// type. See [FileIsOfType].
+var fileTypeExtensions = map[string][]string{
+	"go":       {".go"},
+	"py":       {".py", ".pyi"},
+	"sh":       {".sh", ".bash", ".ksh"},
+	"tf":       {".tf", ".tfvars"},
+	"yaml":     {".yaml", ".yml"},
+	"md":       {".md", ".markdown", ".mdown", ".mkdn"},
+	"protobuf": {".proto"},
+}
+
+// containerfileNames are the base names (in lowercase) of container build files.
+var containerfileNames = []string{"containerfile", "dockerfile"}
+
+// FileIsOfType reports whether the file at the provided path is of the provided file type, e.g.
+// "go", "yaml", or "containerfile". Most file types are matched by extension, but container build
+// files are matched by base name, including variants like "Containerfile.dev" &
+// "app.Dockerfile".
+func FileIsOfType(path string, fileType string) bool {
+	if fileType == "containerfile" {
+		base := strings.ToLower(filepath.Base(path))
+		for _, name := range containerfileNames {
+			if base == name || strings.HasPrefix(base, name+".") || strings.HasSuffix(base, "."+name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return slices.Contains(fileTypeExtensions[fileType], filepath.Ext(path))
+}
+
+// FilesOfType returns the files from the provided list that are of the provided file type. See
+// [FileIsOfType].
+func FilesOfType(files []string, fileType string) []string {
+	out := make([]string, 0)
+	for _, file := range files {
+		if FileIsOfType(file, fileType) {
+			out = append(out, file)
+		}
+	}
+
+	return out
+}
+
+// FilesMatching returns the files from the provided list that match any of the provided
+// gitignore-style glob patterns, e.g. "*.sql" or "/db/**/*.sql". Like in a `.gitignore` file, a
+// pattern with no slash in it matches at any depth, and any other pattern is relative to the root
+// of the listed paths. A pattern ending in "/" matches everything in that directory.
+func FilesMatching(files []string, patterns []string) ([]string, error) {
+	regexes := make([]*regexp.Regexp, 0)
+	for _, pattern := range patterns {
+		if strings.Trim(pattern, "/") == "" {
+			return nil, fmt.Errorf("file pattern '%s' is empty", pattern)
+		}
+
+		glob := pattern
+		if strings.HasSuffix(glob, "/") {
+			if !strings.Contains(strings.TrimSuffix(glob, "/"), "/") {
+				// A trailing slash alone doesn't anchor the pattern
+				glob = "**/" + glob
+			}
+			glob += "**"
+		}
+
+		regex, err := compileGlob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern '%s': %w", pattern, err)
+		}
+		regexes = append(regexes, regex)
+	}
+
+	out := make([]string, 0)
+	for _, file := range files {
+		if slices.ContainsFunc(regexes, func(r *regexp.Regexp) bool { return r.MatchString(file) }) {
+			out = append(out, file)
+		}
+	}
+
+	return out, nil
+}
+
+// ListFiles returns the path of every file under root (relative to it, and slash-separated),
+// skipping any that Git would ignore via `.gitignore` files, `.git/info/exclude`, or the user's
+// global excludes file. Hidden files are included, but the `.git` directory itself is not, and
+// neither is anything in a nested Git repo (like a submodule). Symlinks are only included if they
+// point to a regular file, so symlinked directories aren't listed or followed.
+func ListFiles(ctx context.Context, root string) ([]string, error) {
+	rules := make(ignoreRules, 0)
+	for _, ignoreFile := range []string{
+		globalExcludesFile(ctx, root),
+		filepath.Join(root, ".git", "info", "exclude"),
+	} {
+		if ignoreFile == "" {
+			continue
+		}
+
+		fileRules, err := readIgnoreFile(ignoreFile, "")
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	out := make([]string, 0)
+	if err := walkDir(root, "", rules, &out); err != nil {
+		return nil, fmt.Errorf("listing files: %w", err)
+	}
+	slices.Sort(out)
+
+	iprint.Debugf("found %d files under '%s'\n", len(out), root)
+
+	return out, nil
+}
+
+// walkDir adds every non-ignored file under the relative directory rel to out, recursing into
+// subdirectories. Any `.gitignore` file in rel adds to the provided rules, for rel & below only.
+func walkDir(root string, rel string, rules ignoreRules, out *[]string) error {
+	dir := filepath.Join(root, filepath.FromSlash(rel))
+
+	dirRules, err := readIgnoreFile(filepath.Join(dir, ".gitignore"), rel)
+	if err != nil {
+		return err
+	}
+	// NOTE: always a new slice, so that sibling directories never see each other's rules
+	rules = slices.Concat(rules, dirRules)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		relPath := joinRel(rel, entry.Name())
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" || rules.ignores(relPath, true) {
+				continue
+			}
+			// Like Git, don't descend into nested repos, whose `.git` is a directory, or a file for
+			// submodules & worktrees
+			if _, err := os.Lstat(filepath.Join(dir, entry.Name(), ".git")); err == nil {
+				iprint.Debugf("skipping nested Git repo '%s'\n", relPath)
+				continue
+			}
+			if err := walkDir(root, relPath, rules, out); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if entry.Type()&os.ModeSymlink != 0 {
+			// Symlinks to directories (or to nothing) can't be read like files
+			if info, err := os.Stat(filepath.Join(dir, entry.Name())); err != nil || !info.Mode().IsRegular() {
+				iprint.Debugf("skipping symlink '%s' that doesn't point to a file\n", relPath)
+				continue
+			}
+		} else if !entry.Type().IsRegular() {
+			// Skip anything else that isn't a regular file, e.g. sockets
+			continue
+		}
+
+		if !rules.ignores(relPath, false) {
+			*out = append(*out, relPath)
+		}
+	}
+
+	return nil
+}
+
+// globalExcludesFile returns the path to the user's global Git excludes file, per Git's
+// `core.excludesFile` config or its default location. It returns an empty string if the path
+// can't be determined.
+func globalExcludesFile(ctx context.Context, root string) string {
+	cmd := exec.CommandContext(ctx, "git", "config", "--path", "--get", "core.excludesFile")
+	cmd.Dir = root
+	if output, err := cmd.Output(); err == nil && strings.TrimSpace(string(output)) != "" {
+		return strings.TrimSpace(string(output))
+	}
+
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, "git", "ignore")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "git", "ignore")
+}
@@ -0,0 +1,162 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFiles(t *testing.T) {
+	root := t.TempDir()
+	// Don't let the host's global excludes file affect the test
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	for path, contents := range map[string]string{
+		".gitignore":          "*.log\n/dist/\n",
+		".git/info/exclude":   "secret.txt\n",
+		".git/HEAD":           "ref: refs/heads/main\n",
+		".github/ci.yaml":     "",
+		"main.go":             "",
+		"debug.log":           "",
+		"secret.txt":          "",
+		"dist/app":            "",
+		"pkg/dist/keep.go":    "",
+		"pkg/.gitignore":      "*.gen.go\n",
+		"pkg/a.go":            "",
+		"pkg/a.gen.go":        "",
+		"other/b.gen.go":      "",
+		"Containerfile":       "",
+		"deploy/app.tfvars":   "",
+		"docs/nested/doc.md":  "",
+		"docs/nested/out.log": "",
+	} {
+		fullPath := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(contents), 0644))
+	}
+
+	got, err := ListFiles(context.Background(), root)
+	require.NoError(t, err)
+
+	want := []string{
+		".github/ci.yaml",
+		".gitignore",
+		"Containerfile",
+		"deploy/app.tfvars",
+		"docs/nested/doc.md",
+		"main.go",
+		"other/b.gen.go",
+		"pkg/.gitignore",
+		"pkg/a.go",
+		"pkg/dist/keep.go",
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestListFilesNestedRepos(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	for path, contents := range map[string]string{
+		".git/HEAD":               "ref: refs/heads/main\n",
+		"main.go":                 "",
+		"vendor/nested/.git/HEAD": "ref: refs/heads/main\n",
+		"vendor/nested/lib.go":    "",
+		"vendor/submodule/.git":   "gitdir: ../../.git/modules/submodule\n",
+		"vendor/submodule/x.go":   "",
+		"vendor/plain/y.go":       "",
+	} {
+		fullPath := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(contents), 0644))
+	}
+
+	got, err := ListFiles(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go", "vendor/plain/y.go"}, got)
+}
+
+func TestListFilesSymlinks(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "real"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "real", "a.go"), nil, 0644))
+	require.NoError(t, os.Symlink("real", filepath.Join(root, "linked-dir")))
+	require.NoError(t, os.Symlink(filepath.Join("real", "a.go"), filepath.Join(root, "linked.go")))
+	require.NoError(t, os.Symlink("nowhere", filepath.Join(root, "broken.go")))
+	// A loop, which would never finish if followed
+	require.NoError(t, os.Symlink("..", filepath.Join(root, "real", "parent")))
+
+	got, err := ListFiles(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linked.go", "real/a.go"}, got)
+}
+
+func TestFileIsOfType(t *testing.T) {
+	tt := []struct {
+		Path     string
+		FileType string
+		Want     bool
+	}{
+		{Path: "Containerfile", FileType: "containerfile", Want: true},
+		{Path: "build/Dockerfile", FileType: "containerfile", Want: true},
+		{Path: "Containerfile.dev", FileType: "containerfile", Want: true},
+		{Path: "build/app.Dockerfile", FileType: "containerfile", Want: true},
+		{Path: "docs/dockerfile-tips.md", FileType: "containerfile", Want: false},
+		{Path: "docs/dockerfile-tips.md", FileType: "md", Want: true},
+		{Path: ".github/workflows/main.yml", FileType: "yaml", Want: true},
+		{Path: "scripts/run.bash", FileType: "sh", Want: true},
+		{Path: "main.go", FileType: "py", Want: false},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Path+"/"+s.FileType, func(t *testing.T) {
+			assert.Equal(t, s.Want, FileIsOfType(s.Path, s.FileType))
+		})
+	}
+}
+
+func TestFilesMatching(t *testing.T) {
+	files := []string{
+		"schema.sql",
+		"db/migrations/001.sql",
+		"db/seed.sql",
+		"build/out.txt",
+		"tools/build/gen.txt",
+		"main.go",
+	}
+
+	tt := []struct {
+		Name     string
+		Patterns []string
+		Want     []string
+	}{
+		{Name: "any depth", Patterns: []string{"*.sql"}, Want: []string{"schema.sql", "db/migrations/001.sql", "db/seed.sql"}},
+		{Name: "anchored", Patterns: []string{"/*.sql"}, Want: []string{"schema.sql"}},
+		{Name: "relative to root", Patterns: []string{"db/*.sql"}, Want: []string{"db/seed.sql"}},
+		{Name: "double star", Patterns: []string{"db/**/*.sql"}, Want: []string{"db/migrations/001.sql", "db/seed.sql"}},
+		{Name: "directory", Patterns: []string{"build/"}, Want: []string{"build/out.txt", "tools/build/gen.txt"}},
+		{Name: "anchored directory", Patterns: []string{"/build/"}, Want: []string{"build/out.txt"}},
+		{Name: "several", Patterns: []string{"main.go", "seed.sql"}, Want: []string{"db/seed.sql", "main.go"}},
+		{Name: "no matches", Patterns: []string{"*.rs"}, Want: []string{}},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, err := FilesMatching(files, s.Patterns)
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+
+	_, err := FilesMatching(files, []string{"/"})
+	assert.Error(t, err)
+}
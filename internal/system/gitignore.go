@@ -0,0 +1,180 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// An ignoreRule is a single pattern from a gitignore-style file.
+type ignoreRule struct {
+	// The slash-separated directory (relative to the walk root) that the rule's file lives in, and
+	// that anchored patterns are relative to. Empty for the walk root itself.
+	base string
+	// The compiled pattern, matched against paths relative to base.
+	regex *regexp.Regexp
+	// Whether the pattern started with "!", i.e. it re-includes paths that an earlier rule ignored.
+	negate bool
+	// Whether the pattern ended with "/", i.e. it only matches directories.
+	dirOnly bool
+}
+
+// ignoreRules is an ordered list of [ignoreRule]s, where later rules take precedence.
+type ignoreRules []ignoreRule
+
+// ignores reports whether the provided slash-separated path (relative to the walk root) is ignored.
+func (rules ignoreRules) ignores(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		rel := relPath
+		if rule.base != "" {
+			if !strings.HasPrefix(relPath, rule.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(relPath, rule.base+"/")
+		}
+
+		if rule.regex.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// readIgnoreFile parses the gitignore-style file at filePath into [ignoreRules] relative to base. A
+// missing file is not an error, and just has no rules.
+func readIgnoreFile(filePath string, base string) (ignoreRules, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading ignore file '%s': %w", filePath, err)
+	}
+
+	return parseIgnoreRules(data, base), nil
+}
+
+// parseIgnoreRules parses the contents of a gitignore-style file into [ignoreRules] relative to
+// base. See `man gitignore` for the pattern format.
+func parseIgnoreRules(data []byte, base string) ignoreRules {
+	out := make(ignoreRules, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			// Escaped leading "!" or "#"
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		if strings.TrimPrefix(line, "/") == "" {
+			continue
+		}
+
+		regex, err := compileGlob(line)
+		if err != nil {
+			// Git silently skips patterns it can't make sense of, so do the same
+			continue
+		}
+		rule.regex = regex
+
+		out = append(out, rule)
+	}
+
+	return out
+}
+
+// compileGlob compiles a single gitignore glob pattern (without any leading "!" or trailing "/")
+// into a regular expression that matches the whole of a slash-separated relative path. Patterns
+// with a slash in them are relative to the root of those paths, otherwise they can match at any
+// depth below it.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(glob, "/")
+	glob = strings.TrimPrefix(glob, "/")
+
+	prefix := "^"
+	if !anchored {
+		prefix = "^(?:.*/)?"
+	}
+
+	return regexp.Compile(prefix + globToRegex(glob) + "$")
+}
+
+// globToRegex converts a single gitignore glob pattern into an (unanchored) regular expression.
+func globToRegex(glob string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// Zero or more leading directories
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			// Everything inside the directory
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**") && i+2 == len(glob):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
+
+// joinRel joins slash-separated relative path elements, treating an empty dir as the walk root.
+func joinRel(dir string, name string) string {
+	if dir == "" {
+		return name
+	}
+
+	return path.Join(dir, name)
+}
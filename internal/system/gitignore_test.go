@@ -0,0 +1,62 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules := parseIgnoreRules([]byte(`
+# comment
+*.log
+!keep.log
+build/
+/root-only.txt
+docs/**/*.tmp
+vendor/**
+\#literal
+`), "")
+
+	tt := []struct {
+		Path        string
+		IsDir       bool
+		WantIgnored bool
+	}{
+		{Path: "app.log", WantIgnored: true},
+		{Path: "nested/dir/app.log", WantIgnored: true},
+		{Path: "nested/keep.log", WantIgnored: false},
+		{Path: "build", IsDir: true, WantIgnored: true},
+		{Path: "src/build", IsDir: true, WantIgnored: true},
+		{Path: "build", IsDir: false, WantIgnored: false},
+		{Path: "root-only.txt", WantIgnored: true},
+		{Path: "sub/root-only.txt", WantIgnored: false},
+		{Path: "docs/a.tmp", WantIgnored: true},
+		{Path: "docs/a/b/c.tmp", WantIgnored: true},
+		{Path: "other/a.tmp", WantIgnored: false},
+		{Path: "vendor/github.com/x/y.go", WantIgnored: true},
+		{Path: "#literal", WantIgnored: true},
+		{Path: "main.go", WantIgnored: false},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Path, func(t *testing.T) {
+			assert.Equal(t, s.WantIgnored, rules.ignores(s.Path, s.IsDir))
+		})
+	}
+}
+
+func TestIgnoreRulesNested(t *testing.T) {
+	rules := append(
+		parseIgnoreRules([]byte("*.gen.go\n"), ""),
+		parseIgnoreRules([]byte("!keep.gen.go\n/local.txt\n"), "sub")...,
+	)
+
+	assert.True(t, rules.ignores("a.gen.go", false))
+	assert.True(t, rules.ignores("sub/a.gen.go", false))
+	assert.False(t, rules.ignores("sub/keep.gen.go", false))
+	assert.True(t, rules.ignores("keep.gen.go", false))
+	assert.True(t, rules.ignores("sub/local.txt", false))
+	assert.False(t, rules.ignores("local.txt", false))
+	assert.False(t, rules.ignores("sub/deeper/local.txt", false))
+}
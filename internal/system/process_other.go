@@ -0,0 +1,9 @@
+//go:build !unix
+
+package system
+
+import "os/exec"
+
+// setProcessGroup does nothing on platforms without Unix process groups, so canceling a command only
+// kills the command itself.
+func setProcessGroup(_ *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package system
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup makes the command run in its own process group, and makes canceling it kill that
+// whole group. Otherwise, only the command itself would be killed, leaving behind anything it
+// started -- e.g. the tool that mise runs, or the containers that a build runs.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
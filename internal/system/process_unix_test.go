@@ -0,0 +1,23 @@
+//go:build unix
+
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHostCommandCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	// The shell's child keeps the output open, so this only returns quickly if the child is killed
+	// along with the shell
+	start := time.Now()
+	_, err := RunHostCommand(ctx, []string{"sh", "-c", "sleep 30; echo done"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), cancelWaitDelay)
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
@@ -89,7 +91,7 @@ func Init(ctx context.Context) error {
 // error message in case of failure. It also returns the command output, in case the caller needs to
 // parse it on their own.
 func RunCommand(ctx context.Context, cmdArgs []string) (string, error) {
-	if len(cmdArgs) <= 1 {
+	if len(cmdArgs) == 0 {
 		return "", fmt.Errorf("internal error: not enough arguments passed to RunCommand() -- received: %v", cmdArgs)
 	}
 
@@ -100,35 +102,121 @@ func RunCommand(ctx context.Context, cmdArgs []string) (string, error) {
 		args = slices.Concat([]string{"exec", "--"}, cmdArgs)
 	}
 
-	cmd := exec.CommandContext(ctx, consts.MiseBinPath, args...)
-	iprint.Debugf("Running '%v'\n", cmd.Args)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
+	return runCommand(ctx, exec.CommandContext(ctx, consts.MiseBinPath, args...))
+}
+
+// RunHostCommand is like [RunCommand], but runs the command directly on the host instead of through
+// mise. This should only be used for commands that oscar requires the host to provide itself (like
+// `bash` & `git`), and is useful for subcommands that don't need the rest of oscar's tooling
+// installed.
+func RunHostCommand(ctx context.Context, cmdArgs []string) (string, error) {
+	if len(cmdArgs) == 0 {
+		return "", fmt.Errorf("internal error: not enough arguments passed to RunHostCommand() -- received: %v", cmdArgs)
+	}
+
+	return runCommand(ctx, exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...))
+}
+
+// cancelWaitDelay is how long to wait for a canceled command's output to close before giving up on
+// it. See [exec.Cmd.WaitDelay].
+const cancelWaitDelay = 5 * time.Second
+
+// runCommand runs the provided command, returning its combined output & a consistent error message
+// in case of failure. The output is also written to any writer set via [WithOutput], and the command
+// is run from any directory set via [WithWorkDir], with any environment variables set via
+// [WithEnv], and reading any input set via [WithInput]. If ctx is canceled, the command & everything
+// it started are killed.
+func runCommand(ctx context.Context, cmd *exec.Cmd) (string, error) {
+	if dir, ok := ctx.Value(workDirKey{}).(string); ok {
+		cmd.Dir = dir
+	}
+	if env, ok := ctx.Value(envKey{}).([]string); ok {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if input, ok := ctx.Value(inputKey{}).(io.Reader); ok {
+		cmd.Stdin = input
+	}
+	setProcessGroup(cmd)
+	// Don't wait forever on output from anything that outlives the command after it's canceled
+	cmd.WaitDelay = cancelWaitDelay
+	iprint.Debugf("Running '%v' (in '%s')\n", cmd.Args, WorkDir(ctx))
+
+	var output bytes.Buffer
+	var w io.Writer = &output
+	if ctxWriter, ok := ctx.Value(outputKey{}).(io.Writer); ok {
+		w = io.MultiWriter(&output, ctxWriter)
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if err := cmd.Run(); err != nil {
 		return "", fmt.Errorf(
 			"running '%v': %w, with output:\n%s",
-			cmd.Args, err, string(output),
+			cmd.Args, err, output.String(),
 		)
 	}
 
-	return strings.TrimSuffix(string(output), "\n"), nil
+	return strings.TrimSuffix(output.String(), "\n"), nil
 }
 
-// GetFileTypeListerCommand takes a [ripgrep]-known file type, and returns a string (to be used as
-// an arg after `bash -c`) representing a command & its args to find matching files. ripgrep is used
-// as the default file-finder across the codebase because not only is it fast, but it also supports
-// files like `.gitignore` without any extra configuration.
-//
-// [ripgrep]: https://github.com/BurntSushi/ripgrep
-func GetFileTypeListerCommand(fileType string) string {
-	// NOTE: there are some special cases we need to handle, like how ripgrep understands "docker"
-	// as a file type arg (and it will find files matching the glob "*Dockerfile*"), but it will
-	// *not* find e.g. "Containerfile"
-	switch fileType {
-	case "containerfile":
-		return `rg --hidden --files --glob-case-insensitive --glob='*{Containerfile,Dockerfile}*' || true`
-	default:
-		return fmt.Sprintf(`rg --hidden --files --type '%s' || true`, fileType)
+// outputKey is the context key used by [WithOutput].
+type outputKey struct{}
+
+// WithOutput returns a copy of ctx that makes [RunCommand] & [RunHostCommand] also write their
+// commands' combined output to w. This lets callers capture everything a Task ran, without every
+// Task needing to pass its output along itself.
+func WithOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, outputKey{}, w)
+}
+
+// workDirKey is the context key used by [WithWorkDir].
+type workDirKey struct{}
+
+// WithWorkDir returns a copy of ctx that makes [RunCommand] & [RunHostCommand] run their commands
+// from dir, instead of from oscar's own working directory. This lets the same Task run against
+// several project roots in a single run, since a process can only have one working directory.
+func WithWorkDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workDirKey{}, dir)
+}
+
+// WorkDir returns the directory set via [WithWorkDir], or "." if none was set.
+func WorkDir(ctx context.Context) string {
+	if dir, ok := ctx.Value(workDirKey{}).(string); ok && dir != "" {
+		return dir
 	}
+
+	return "."
+}
+
+// envKey is the context key used by [WithEnv].
+type envKey struct{}
+
+// WithEnv returns a copy of ctx that makes [RunCommand] & [RunHostCommand] run their commands with
+// the provided "KEY=value" environment variables set, on top of oscar's own environment. Any
+// variables already set via WithEnv are kept.
+func WithEnv(ctx context.Context, env ...string) context.Context {
+	existing, _ := ctx.Value(envKey{}).([]string)
+	return context.WithValue(ctx, envKey{}, slices.Concat(existing, env))
+}
+
+// inputKey is the context key used by [WithInput].
+type inputKey struct{}
+
+// WithInput returns a copy of ctx that makes [RunCommand] & [RunHostCommand] pass r to their
+// commands' standard input. This is useful for passing secrets to a command without them showing up
+// in its args.
+func WithInput(ctx context.Context, r io.Reader) context.Context {
+	return context.WithValue(ctx, inputKey{}, r)
+}
+
+// ResolvePath returns the provided path as seen from the directory set via [WithWorkDir], for Tasks
+// that work with files directly instead of through a command. Absolute paths are returned as-is.
+func ResolvePath(ctx context.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(WorkDir(ctx), path)
 }
 
 // installMise installs [mise] into [consts.OscarHomeBin], if not found there.
@@ -210,27 +298,3 @@ func installMise(_ context.Context) (err error) {
 
 	return err
 }
-
-// FilesExistInTree performs file discovery by allowing various tools to check if they need to run
-// based on file presence.
-func FilesExistInTree(ctx context.Context, findScript string) (bool, error) {
-	cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf(`
-		shopt -s globstar
-		%s`,
-		findScript,
-	))
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// If no files found, that's fine, just report it
-		if strings.Contains(string(output), "No such file or directory") {
-			return false, nil
-		}
-		return false, fmt.Errorf("finding files: %w -- output:\n%s", err, string(output))
-	}
-
-	if string(output) == "" {
-		return false, nil
-	}
-
-	return true, nil
-}
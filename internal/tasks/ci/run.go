@@ -4,48 +4,126 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"time"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
+	"github.com/opensourcecorp/oscar"
+	"github.com/opensourcecorp/oscar/internal/cache"
 	"github.com/opensourcecorp/oscar/internal/consts"
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
 	igit "github.com/opensourcecorp/oscar/internal/git"
+	"github.com/opensourcecorp/oscar/internal/oscarcfg"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 	containertools "github.com/opensourcecorp/oscar/internal/tasks/tools/containers"
+	customtools "github.com/opensourcecorp/oscar/internal/tasks/tools/custom"
 	gotools "github.com/opensourcecorp/oscar/internal/tasks/tools/go"
 	mdtools "github.com/opensourcecorp/oscar/internal/tasks/tools/markdown"
+	prototools "github.com/opensourcecorp/oscar/internal/tasks/tools/protobuf"
 	pytools "github.com/opensourcecorp/oscar/internal/tasks/tools/python"
 	shtools "github.com/opensourcecorp/oscar/internal/tasks/tools/shell"
+	tftools "github.com/opensourcecorp/oscar/internal/tasks/tools/terraform"
+	"github.com/opensourcecorp/oscar/internal/tasks/tools/toolcfg"
 	versiontools "github.com/opensourcecorp/oscar/internal/tasks/tools/version"
 	yamltools "github.com/opensourcecorp/oscar/internal/tasks/tools/yaml"
 	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
 )
 
-// getCITaskMap assembles the overall list of CI tasks, keyed by their language/tooling name
-func getCITaskMap(repo taskutil.Repo) (taskutil.TaskMap, error) {
+// getCITaskMap assembles the overall list of CI tasks for a single project, keyed by their
+// language/tooling name. Any custom tasks from the oscar config file are included too, but none of
+// them may take the place of one of oscar's own tasks.
+func getCITaskMap(project taskutil.Project, customTasks []*oscarcfgpbv1.CustomTask) (taskutil.TaskMap, error) {
 	out := make(taskutil.TaskMap)
 	for langName, getTasksFunc := range map[string]func(taskutil.Repo) []taskutil.Tasker{
-		"Versioning": versiontools.NewTasksForCI,
-		"Go":         gotools.NewTasksForCI,
-		"Python":     pytools.NewTasksForCI,
-		// "Terraform":     tftools.NewTasksForCI,
+		"Versioning":    versiontools.NewTasksForCI,
+		"Go":            gotools.NewTasksForCI,
+		"Python":        pytools.NewTasksForCI,
+		"Terraform":     tftools.NewTasksForCI,
 		"YAML":          yamltools.NewTasksForCI,
 		"Containerfile": containertools.NewTasksForCI,
 		"Shell":         shtools.NewTasksForCI,
 		"Markdown":      mdtools.NewTasksForCI,
+		"Protobuf":      prototools.NewTasksForCI,
 	} {
-		tasks := getTasksFunc(repo)
+		// The version is only tracked in the oscar config file at the repo root, so it only needs
+		// checking once
+		if langName == "Versioning" && project.Root != taskutil.RootProject {
+			continue
+		}
+
+		tasks := getTasksFunc(project.Repo)
 		if len(tasks) > 0 {
 			out[langName] = tasks
 		}
 	}
 
+	custom, err := customtools.NewTasksForCI(project, customTasks)
+	if err != nil {
+		return nil, err
+	}
+	for group, tasks := range custom {
+		for _, task := range tasks {
+			builtIn := slices.ContainsFunc(out[group], func(t taskutil.Tasker) bool {
+				return t.InfoText() == task.InfoText()
+			})
+			if builtIn {
+				return nil, fmt.Errorf(
+					"custom task '%s' in group '%s' has the same name as one of oscar's own tasks, which can't be overridden",
+					task.InfoText(), group,
+				)
+			}
+		}
+		out[group] = append(out[group], tasks...)
+	}
+
 	iprint.Debugf("getCITaskMap output: %#v\n", out)
 
 	return out, nil
 }
 
-// Run defines the behavior for running all CI tasks for the repository.
-func Run(ctx context.Context) (err error) {
+// newTaskCache returns the cache for CI Task results. Keys are salted with everything that oscar
+// itself brings to a Task: its own version, the versions of the tools it installs, and its config
+// files for those tools. So changing any of them invalidates every cached result, rather than
+// risking a stale one.
+func newTaskCache() (*cache.Cache, error) {
+	salts := make([][]byte, 0)
+	for _, name := range []string{"mise.toml", consts.DefaultOscarCfgFileName} {
+		data, err := oscar.Files.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading embedded file contents: %w", err)
+		}
+		salts = append(salts, []byte(name), data)
+	}
+
+	err := fs.WalkDir(toolcfg.Files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := toolcfg.Files.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		salts = append(salts, []byte(path), data)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded tool config files: %w", err)
+	}
+
+	return cache.New(consts.OscarTaskCacheDir, salts...), nil
+}
+
+// Run defines the behavior for running all CI tasks for the repository. If [taskutil.RunOptions.Fix]
+// is set, only the [taskutil.Tool.Mutating] tasks are run, and any changes they make to files are
+// kept & reported instead of failing the run. If [taskutil.RunOptions.Staged] is set, any unstaged
+// changes are set aside while the tasks run, so that they check exactly what is staged.
+func Run(ctx context.Context, opts taskutil.RunOptions) (err error) {
 	// The mise config that oscar uses is written during init, so be sure to defer its removal here
 	defer func() {
 		if rmErr := os.RemoveAll(consts.MiseConfigFileName); rmErr != nil {
@@ -53,68 +131,154 @@ func Run(ctx context.Context) (err error) {
 		}
 	}()
 
-	run, err := taskutil.NewRun(ctx, "CI")
+	if opts.Staged {
+		// Check exactly what's about to be committed, without any unstaged changes getting in the
+		// way. Those are put back even if the run is interrupted, since that only cancels ctx.
+		setAside, err := igit.SetAsideUnstaged(ctx)
+		if err != nil {
+			return fmt.Errorf("setting aside unstaged changes: %w", err)
+		}
+		defer func() {
+			if restoreErr := setAside.Restore(ctx); restoreErr != nil {
+				err = errors.Join(err, restoreErr)
+			}
+		}()
+	}
+
+	runType := "CI"
+	if opts.Fix {
+		runType = "Fix"
+	}
+
+	run, err := taskutil.NewRun(ctx, runType, opts)
 	if err != nil {
 		return fmt.Errorf("internal error setting up run info: %w", err)
 	}
 
-	taskMap, err := getCITaskMap(run.Repo)
+	if !opts.NoCache {
+		run.Cache, err = newTaskCache()
+		if err != nil {
+			return fmt.Errorf("internal error setting up task cache: %w", err)
+		}
+	}
+
+	customTasks, err := configuredCustomTasks()
 	if err != nil {
 		return err
 	}
 
+	projectTaskMap := make(taskutil.ProjectTaskMap)
+	for _, project := range run.Projects {
+		taskMap, err := getCITaskMap(project, customTasks)
+		if err != nil {
+			return err
+		}
+		if opts.Fix {
+			taskMap = taskMap.Mutating()
+		}
+		if len(taskMap) > 0 {
+			projectTaskMap[project.Root] = taskMap
+		}
+	}
+
+	// Some tools need their config file (or other files) at a relative path inside the repo, which
+	// they create & remove themselves -- those files should not count as changes, even when seen by a
+	// check for a different, concurrently-running Task
+	ignoredPaths := make([]string, 0)
+	for root, taskMap := range projectTaskMap {
+		for _, tasks := range taskMap {
+			for _, task := range tasks {
+				if cfgPath := task.ToolInfo().ConfigFilePath; cfgPath != "" && !filepath.IsAbs(cfgPath) {
+					ignoredPaths = append(ignoredPaths, path.Join(root, filepath.ToSlash(cfgPath)))
+				}
+				for _, tempPath := range task.ToolInfo().TempPaths {
+					ignoredPaths = append(ignoredPaths, path.Join(root, filepath.ToSlash(tempPath)))
+				}
+			}
+		}
+	}
+
 	// For tracking any changes to Git status etc. after each CI Task runs
-	gitCI, err := igit.NewForCI(ctx)
+	gitCI, err := igit.NewForCI(ctx, ignoredPaths...)
 	if err != nil {
 		return fmt.Errorf("internal error: %w", err)
 	}
 
-	for _, lang := range taskMap.SortedKeys() {
-		tasks := taskMap[lang]
-
-		run.PrintTaskMapBanner(lang)
-		for _, task := range tasks {
-			taskStartTime := time.Now()
-			run.PrintTaskBanner(task)
-
-			// NOTE: this error is checked later, when we can check the Run, Post, and git-diff
-			// potential errors together
-			var runErr error
-			runErr = errors.Join(runErr, task.Exec(ctx))
-			runErr = errors.Join(runErr, task.Post(ctx))
+	// Changes made by each Task in fix mode, keyed by Task ID
+	var fixedMu sync.Mutex
+	fixed := make(map[string]igit.Changes)
+	fixedChanges := func(id string) (igit.Changes, bool) {
+		fixedMu.Lock()
+		defer fixedMu.Unlock()
+		changes, found := fixed[id]
+		return changes, found
+	}
 
-			if err := gitCI.Update(ctx); err != nil {
-				return fmt.Errorf("internal error: %w", err)
-			}
-			gitStatusHasChanged, err := gitCI.StatusHasChanged(ctx)
+	hooks := taskutil.TaskHooks{
+		Start: gitCI.TaskStarted,
+		Finish: func(ctx context.Context, id string) error {
+			changes, err := gitCI.TaskFinished(ctx, id)
 			if err != nil {
 				return fmt.Errorf("internal error: %w", err)
 			}
 
-			if runErr != nil || gitStatusHasChanged {
-				iprint.Errorf("FAILED (%s)\n", iprint.RunDurationString(taskStartTime))
-				iprint.Errorf("\n")
+			if !changes.HasChanges() {
+				return nil
+			}
 
-				if runErr != nil {
-					iprint.Errorf("%v\n", runErr)
-				}
+			if opts.Fix {
+				fixedMu.Lock()
+				defer fixedMu.Unlock()
+				fixed[id] = changes
+				return nil
+			}
 
-				if gitStatusHasChanged {
-					iprint.Errorf("Files ~CHANGED~ during run: %+v\n", gitCI.CurrentStatus.Diff)
-					iprint.Errorf("Files +CREATED+ during run: %+v\n", gitCI.CurrentStatus.UntrackedFiles)
-					iprint.Errorf("\n")
-				}
+			return &igit.ChangesError{Changes: changes}
+		},
+	}
 
-				run.Failures = append(run.Failures, fmt.Sprintf("%s :: %s", lang, task.InfoText()))
+	results, err := run.Execute(ctx, projectTaskMap, hooks, func(result taskutil.Result) {
+		run.PrintTaskBanner(result.Task)
 
-				// Also need to reset the baseline status
-				gitCI, err = igit.NewForCI(ctx)
-				if err != nil {
-					return fmt.Errorf("internal error: %w", err)
-				}
-			} else {
-				iprint.Goodf("PASSED (%s)\n", iprint.RunDurationString(taskStartTime))
+		if result.Status == taskutil.StatusSkipped {
+			iprint.Infof(run.Colors.WarnColor + "SKIPPED\n" + run.Colors.Reset)
+			run.Skipped = append(run.Skipped, result.ID())
+		} else if result.Err != nil {
+			iprint.Errorf("%s (%s)\n", result.Status, iprint.DurationString(result.Duration()))
+			iprint.Errorf("\n")
+			iprint.Errorf("%v\n", result.Err)
+			iprint.Errorf("\n")
+			printChangesDiff(result.Err, opts.DiffMaxLines)
+
+			run.Failures = append(run.Failures, result.FailureText())
+		} else if result.Cached {
+			iprint.Goodf("PASSED (cached)\n")
+		} else if changes, found := fixedChanges(result.ID()); found {
+			iprint.Goodf("FIXED (%s)\n", iprint.DurationString(result.Duration()))
+			for _, entry := range changes.Entries {
+				iprint.Infof("  - %s\n", entry)
 			}
+		} else {
+			iprint.Goodf("PASSED (%s)\n", iprint.DurationString(result.Duration()))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	opts.Recorder.Add(run, results)
+
+	if opts.PatchFile != "" {
+		if err := writePatchFile(ctx, gitCI, opts.PatchFile); err != nil {
+			return err
+		}
+	}
+
+	if opts.Fix {
+		if len(fixed) > 0 {
+			iprint.Infof("\n%d task(s) changed files -- review & commit the changes listed above\n", len(fixed))
+		} else {
+			iprint.Infof("\nNothing needed fixing\n")
 		}
 	}
 
@@ -126,3 +290,55 @@ func Run(ctx context.Context) (err error) {
 
 	return err
 }
+
+// configuredCustomTasks returns any custom tasks from the oscar config file. The config file is
+// optional for this, so if there isn't one, there are just no custom tasks.
+func configuredCustomTasks() ([]*oscarcfgpbv1.CustomTask, error) {
+	if _, err := os.Stat(consts.DefaultOscarCfgFileName); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	cfg, err := oscarcfg.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting oscar config: %w", err)
+	}
+
+	return cfg.GetCustomTasks(), nil
+}
+
+// printChangesDiff prints the diff of any files that a Task changed, per its error. Diffs longer
+// than maxLines are cut short, unless maxLines is zero.
+func printChangesDiff(err error, maxLines int) {
+	var changesErr *igit.ChangesError
+	if !errors.As(err, &changesErr) || changesErr.Changes.Patch == "" {
+		return
+	}
+
+	patch := changesErr.Changes.Patch
+	if lineCount := strings.Count(patch, "\n"); maxLines > 0 && lineCount > maxLines {
+		lines := strings.SplitAfter(patch, "\n")
+		patch = strings.Join(lines[:maxLines], "") + fmt.Sprintf("... (%d more lines)\n", lineCount-maxLines)
+	}
+
+	iprint.Infof("Diff of the changes:\n\n%s\n", patch)
+}
+
+// writePatchFile writes a patch of every change that Tasks made during the run to path.
+func writePatchFile(ctx context.Context, gitCI *igit.CI, path string) error {
+	patch, err := gitCI.Patch(ctx)
+	if err != nil {
+		return fmt.Errorf("internal error creating patch: %w", err)
+	}
+
+	if patch == "" {
+		iprint.Infof("No files were changed during the run, so no patch was written\n")
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(patch), 0644); err != nil {
+		return fmt.Errorf("writing patch file: %w", err)
+	}
+	iprint.Infof("Wrote a patch of every change made during the run to '%s' -- apply it with 'git apply %s'\n", path, path)
+
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/opensourcecorp/oscar/internal/consts"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
@@ -45,9 +44,9 @@ func getDeliveryTaskMap(repo taskutil.Repo) (taskutil.TaskMap, error) {
 }
 
 // Run defines the behavior for running all Delivery tasks for the repository.
-func Run(ctx context.Context) (err error) {
+func Run(ctx context.Context, opts taskutil.RunOptions) (err error) {
 	// We intentionally run CI tasks before allowing any Delivery tasks to begin
-	if err := ci.Run(ctx); err != nil {
+	if err := ci.Run(ctx, opts); err != nil {
 		return fmt.Errorf("running CI tasks before Delivery tasks: %w", err)
 	}
 
@@ -58,7 +57,11 @@ func Run(ctx context.Context) (err error) {
 		}
 	}()
 
-	run, err := taskutil.NewRun(ctx, "Deliver")
+	// Delivery tasks publish things (like Git tags) that later tasks rely on, so they always run one
+	// at a time, in order
+	opts.Jobs = 1
+
+	run, err := taskutil.NewRun(ctx, "Deliver", opts)
 	if err != nil {
 		return fmt.Errorf("internal error setting up run info: %w", err)
 	}
@@ -68,32 +71,31 @@ func Run(ctx context.Context) (err error) {
 		return err
 	}
 
-	for _, lang := range taskMap.SortedKeys() {
-		tasks := taskMap[lang]
-
-		run.PrintTaskMapBanner(lang)
-
-		for _, task := range tasks {
-			taskStartTime := time.Now()
-			run.PrintTaskBanner(task)
+	// Deliverables are all declared in the oscar config file at the repo root, so unlike CI, delivery
+	// always runs once, for the whole repo
+	projectTaskMap := taskutil.ProjectTaskMap{taskutil.RootProject: taskMap}
 
-			// NOTE: this error is checked later, when we can check the Run, Post, and git-diff
-			// potential errors together
-			var runErr error
-			runErr = errors.Join(runErr, task.Exec(ctx))
-			runErr = errors.Join(runErr, task.Post(ctx))
+	results, err := run.Execute(ctx, projectTaskMap, taskutil.TaskHooks{}, func(result taskutil.Result) {
+		run.PrintTaskBanner(result.Task)
 
-			if runErr != nil {
-				iprint.Errorf("FAILED    (%s)\n", iprint.RunDurationString(taskStartTime))
-				iprint.Errorf("%v\n", runErr)
+		if result.Status == taskutil.StatusSkipped {
+			iprint.Infof(run.Colors.WarnColor + "SKIPPED\n" + run.Colors.Reset)
+			run.Skipped = append(run.Skipped, result.ID())
+		} else if result.Err != nil {
+			iprint.Errorf("%-9s (%s)\n", result.Status, iprint.DurationString(result.Duration()))
+			iprint.Errorf("%v\n", result.Err)
 
-				run.Failures = append(run.Failures, fmt.Sprintf("%s :: %s", lang, task.InfoText()))
-			} else {
-				iprint.Goodf("SUCCEEDED (%s)\n", iprint.RunDurationString(taskStartTime))
-			}
+			run.Failures = append(run.Failures, result.FailureText())
+		} else {
+			iprint.Goodf("SUCCEEDED (%s)\n", iprint.DurationString(result.Duration()))
 		}
+	})
+	if err != nil {
+		return err
 	}
 
+	opts.Recorder.Add(run, results)
+
 	if len(run.Failures) > 0 {
 		return run.ReportFailure(err)
 	}
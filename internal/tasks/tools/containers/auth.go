@@ -0,0 +1,231 @@
+package containertools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+// The providers that can be set in the `auth` field of a container image's config.
+const (
+	authProviderGHCR         = "ghcr"
+	authProviderDockerHub    = "dockerhub"
+	authProviderEnv          = "env"
+	authProviderDockerConfig = "docker-config"
+)
+
+// The env vars that the "env" auth provider reads, unless the config names others.
+const (
+	defaultUsernameEnv = "OSCAR_REGISTRY_USERNAME"
+	defaultPasswordEnv = "OSCAR_REGISTRY_PASSWORD"
+)
+
+// dockerHubRegistries are the names that Docker Hub goes by.
+var dockerHubRegistries = []string{"docker.io", "index.docker.io", "registry-1.docker.io"}
+
+// registryAuth logs in to a container registry, so that images can be pushed to it.
+type registryAuth interface {
+	// login logs in to the provided registry, using the `login` subcommand of the provided CLI (e.g.
+	// "docker" or "podman") if it needs to run one.
+	login(ctx context.Context, cli string, registry string) error
+}
+
+// newRegistryAuth returns the [registryAuth] for the provided container image config. If the config
+// doesn't set a provider, one is picked based on the image's registry.
+func newRegistryAuth(cfg *oscarcfgpbv1.ContainerImage) (registryAuth, error) {
+	provider := cfg.GetAuth().GetProvider()
+	if provider == "" {
+		provider = defaultAuthProvider(cfg.GetRegistry())
+	}
+
+	switch provider {
+	case authProviderGHCR:
+		return envLogin{
+			usernameEnv: "GITHUB_ACTOR",
+			passwordEnv: "GITHUB_TOKEN",
+			// GHCR only checks the token, so the username just needs to be set to something
+			fallbackUsername: cfg.GetNamespace(),
+		}, nil
+	case authProviderDockerHub:
+		return envLogin{
+			usernameEnv: "DOCKERHUB_USERNAME",
+			passwordEnv: "DOCKERHUB_TOKEN",
+		}, nil
+	case authProviderEnv:
+		auth := envLogin{
+			usernameEnv: cfg.GetAuth().GetUsernameEnv(),
+			passwordEnv: cfg.GetAuth().GetPasswordEnv(),
+		}
+		if auth.usernameEnv == "" {
+			auth.usernameEnv = defaultUsernameEnv
+		}
+		if auth.passwordEnv == "" {
+			auth.passwordEnv = defaultPasswordEnv
+		}
+		return auth, nil
+	case authProviderDockerConfig:
+		return dockerConfigAuth{}, nil
+	default:
+		return nil, fmt.Errorf("unknown registry auth provider '%s'", provider)
+	}
+}
+
+// defaultAuthProvider returns the auth provider to use for the provided registry, if the config
+// doesn't set one.
+func defaultAuthProvider(registry string) string {
+	host := registryHost(registry)
+	switch {
+	case host == "ghcr.io":
+		return authProviderGHCR
+	case isDockerHub(host):
+		return authProviderDockerHub
+	default:
+		return authProviderDockerConfig
+	}
+}
+
+// envLogin runs e.g. `docker login` with a username & password read from env vars.
+type envLogin struct {
+	// The env var holding the username.
+	usernameEnv string
+	// The env var holding the password or token.
+	passwordEnv string
+	// The username to use if usernameEnv isn't set, for registries that don't check it. If empty,
+	// usernameEnv is required.
+	fallbackUsername string
+}
+
+// login implements [registryAuth.login].
+func (a envLogin) login(ctx context.Context, cli string, registry string) error {
+	username := os.Getenv(a.usernameEnv)
+	if username == "" {
+		username = a.fallbackUsername
+	}
+
+	var errs error
+	if username == "" {
+		errs = errors.Join(errs, fmt.Errorf("env var '%s' must be set to the registry username", a.usernameEnv))
+	}
+	password := os.Getenv(a.passwordEnv)
+	if password == "" {
+		errs = errors.Join(errs, fmt.Errorf("env var '%s' must be set to the registry password or token", a.passwordEnv))
+	}
+	if errs != nil {
+		return fmt.Errorf("logging in to '%s': %w", registry, errs)
+	}
+
+	// The password is passed via stdin so that it doesn't show up in the command's args. Container
+	// runtimes come from the host, not mise
+	ctx = system.WithInput(ctx, strings.NewReader(password))
+	if _, err := system.RunHostCommand(ctx, []string{
+		cli, "login", registry, "--username", username, "--password-stdin",
+	}); err != nil {
+		return fmt.Errorf("logging in to '%s': %w", registry, err)
+	}
+
+	return nil
+}
+
+// dockerConfigAuth doesn't log in itself, and instead reuses credentials already stored in the
+// Docker config file, e.g. from the user running `docker login` before oscar. It just checks that
+// there are some for the registry, so that a missing login fails clearly instead of as a push error.
+type dockerConfigAuth struct{}
+
+// dockerConfig is the part of the Docker config file that holds registry credentials.
+type dockerConfig struct {
+	Auths       map[string]json.RawMessage `json:"auths"`
+	CredsStore  string                     `json:"credsStore"`
+	CredHelpers map[string]string          `json:"credHelpers"`
+}
+
+// login implements [registryAuth.login].
+func (a dockerConfigAuth) login(_ context.Context, _ string, registry string) error {
+	path, err := dockerConfigPath()
+	if err != nil {
+		return err
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no Docker config file found at '%s' -- run `docker login %s` first", path, registry)
+		}
+		return fmt.Errorf("reading Docker config file: %w", err)
+	}
+
+	var cfg dockerConfig
+	if err := json.Unmarshal(contents, &cfg); err != nil {
+		return fmt.Errorf("parsing Docker config file '%s': %w", path, err)
+	}
+
+	if !cfg.hasCredentials(registry) {
+		return fmt.Errorf("no credentials for '%s' found in Docker config file '%s' -- run `docker login %s` first", registry, path, registry)
+	}
+
+	return nil
+}
+
+// hasCredentials reports whether the config has credentials for the provided registry. If a
+// credential store is set for every registry, it's assumed to have them, since asking it would mean
+// running its helper.
+func (cfg dockerConfig) hasCredentials(registry string) bool {
+	host := registryHost(registry)
+	for key := range cfg.CredHelpers {
+		if sameRegistry(registryHost(key), host) {
+			return true
+		}
+	}
+	for key := range cfg.Auths {
+		if sameRegistry(registryHost(key), host) {
+			return true
+		}
+	}
+
+	return cfg.CredsStore != ""
+}
+
+// dockerConfigPath returns the path to the Docker config file, which is in the directory set by
+// `DOCKER_CONFIG`, or in `~/.docker` by default.
+func dockerConfigPath() (string, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("finding home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// registryHost returns the host (and port, if any) of a registry, which might be written as a URL
+// like "https://index.docker.io/v1/".
+func registryHost(registry string) string {
+	host := registry
+	if _, rest, found := strings.Cut(host, "://"); found {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+
+	return strings.ToLower(host)
+}
+
+// sameRegistry reports whether two registry hosts are the same registry, treating all of Docker
+// Hub's names as one.
+func sameRegistry(a, b string) bool {
+	return a == b || (isDockerHub(a) && isDockerHub(b))
+}
+
+// isDockerHub reports whether the registry host is Docker Hub.
+func isDockerHub(host string) bool {
+	return slices.Contains(dockerHubRegistries, host)
+}
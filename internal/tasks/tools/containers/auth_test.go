@@ -0,0 +1,158 @@
+package containertools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistryAuth(t *testing.T) {
+	tt := []struct {
+		Name string
+		Cfg  *oscarcfgpbv1.ContainerImage
+		Want registryAuth
+	}{
+		{
+			Name: "ghcr by default",
+			Cfg:  &oscarcfgpbv1.ContainerImage{Registry: "ghcr.io", Namespace: "opensourcecorp"},
+			Want: envLogin{usernameEnv: "GITHUB_ACTOR", passwordEnv: "GITHUB_TOKEN", fallbackUsername: "opensourcecorp"},
+		},
+		{
+			Name: "docker hub by default",
+			Cfg:  &oscarcfgpbv1.ContainerImage{Registry: "docker.io"},
+			Want: envLogin{usernameEnv: "DOCKERHUB_USERNAME", passwordEnv: "DOCKERHUB_TOKEN"},
+		},
+		{
+			Name: "docker config by default",
+			Cfg:  &oscarcfgpbv1.ContainerImage{Registry: "localhost:5000"},
+			Want: dockerConfigAuth{},
+		},
+		{
+			Name: "env with default vars",
+			Cfg: &oscarcfgpbv1.ContainerImage{
+				Registry: "ghcr.io",
+				Auth:     &oscarcfgpbv1.RegistryAuth{Provider: authProviderEnv},
+			},
+			Want: envLogin{usernameEnv: defaultUsernameEnv, passwordEnv: defaultPasswordEnv},
+		},
+		{
+			Name: "env with custom vars",
+			Cfg: &oscarcfgpbv1.ContainerImage{
+				Registry: "quay.io",
+				Auth:     &oscarcfgpbv1.RegistryAuth{Provider: authProviderEnv, UsernameEnv: "QUAY_USER", PasswordEnv: "QUAY_TOKEN"},
+			},
+			Want: envLogin{usernameEnv: "QUAY_USER", passwordEnv: "QUAY_TOKEN"},
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, err := newRegistryAuth(s.Cfg)
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+}
+
+func TestEnvLoginMissingEnv(t *testing.T) {
+	t.Setenv("OSCAR_TEST_USERNAME", "")
+	t.Setenv("OSCAR_TEST_PASSWORD", "")
+
+	auth := envLogin{usernameEnv: "OSCAR_TEST_USERNAME", passwordEnv: "OSCAR_TEST_PASSWORD"}
+	err := auth.login(context.Background(), "docker", "localhost:5000")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "OSCAR_TEST_USERNAME")
+	assert.ErrorContains(t, err, "OSCAR_TEST_PASSWORD")
+}
+
+func TestDockerConfigAuth(t *testing.T) {
+	tt := []struct {
+		Name     string
+		Config   string
+		Registry string
+		WantErr  bool
+	}{
+		{
+			Name:     "auths entry",
+			Config:   `{"auths": {"localhost:5000": {"auth": "dXNlcjpwYXNz"}}}`,
+			Registry: "localhost:5000",
+		},
+		{
+			Name:     "docker hub url",
+			Config:   `{"auths": {"https://index.docker.io/v1/": {}}}`,
+			Registry: "docker.io",
+		},
+		{
+			Name:     "credential helper",
+			Config:   `{"credHelpers": {"123456789012.dkr.ecr.us-east-1.amazonaws.com": "ecr-login"}}`,
+			Registry: "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+		},
+		{
+			Name:     "credential store",
+			Config:   `{"credsStore": "desktop"}`,
+			Registry: "quay.io",
+		},
+		{
+			Name:     "other registry",
+			Config:   `{"auths": {"ghcr.io": {}}}`,
+			Registry: "quay.io",
+			WantErr:  true,
+		},
+		{
+			Name:     "no config file",
+			Registry: "quay.io",
+			WantErr:  true,
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("DOCKER_CONFIG", dir)
+			if s.Config != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(s.Config), 0600))
+			}
+
+			err := dockerConfigAuth{}.login(context.Background(), "docker", s.Registry)
+			if s.WantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestEnvLoginLocalRegistry logs in to a local registry that needs a login.
+func TestEnvLoginLocalRegistry(t *testing.T) {
+	registry := startTestRegistry(t, true)
+	ctx := context.Background()
+	// Don't touch the real Docker config
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	auth, err := newRegistryAuth(&oscarcfgpbv1.ContainerImage{
+		Registry: registry,
+		Auth:     &oscarcfgpbv1.RegistryAuth{Provider: authProviderEnv},
+	})
+	require.NoError(t, err)
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Setenv(defaultUsernameEnv, testRegistryUsername)
+		t.Setenv(defaultPasswordEnv, "wrong")
+		assert.Error(t, auth.login(ctx, "docker", registry))
+	})
+
+	t.Run("right password", func(t *testing.T) {
+		t.Setenv(defaultUsernameEnv, testRegistryUsername)
+		t.Setenv(defaultPasswordEnv, testRegistryPassword)
+		require.NoError(t, auth.login(ctx, "docker", registry))
+
+		// The login should then be reusable
+		assert.NoError(t, dockerConfigAuth{}.login(ctx, "docker", registry))
+	})
+}
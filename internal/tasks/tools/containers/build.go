@@ -0,0 +1,247 @@
+package containertools
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"go.yaml.in/yaml/v4"
+)
+
+// buildSpec describes an image to build & push.
+type buildSpec struct {
+	// The path to the Containerfile.
+	containerfile string
+	// The build context directory.
+	context string
+	// Any build args.
+	args map[string]string
+	// The build stage to build, if not the last one.
+	target string
+	// The platforms to build for, if not just the host's.
+	platforms []string
+	// The digest-pinned image to install QEMU emulators for platforms from, if any should be.
+	emulatorImage string
+	// The image URIs to push the image to.
+	tags []string
+}
+
+// newBuildSpec returns the [buildSpec] for the provided container image config, reading it from the
+// config's Compose file service first, if it has one. It doesn't set any tags.
+func newBuildSpec(cfg *oscarcfgpbv1.ContainerImage) (buildSpec, error) {
+	spec := buildSpec{args: make(map[string]string)}
+
+	if compose := cfg.GetCompose(); compose != nil {
+		service := compose.GetService()
+		if service == "" {
+			service = cfg.GetName()
+		}
+
+		var err error
+		spec, err = buildSpecFromCompose(compose.GetFile(), service)
+		if err != nil {
+			return buildSpec{}, err
+		}
+	}
+
+	if cfg.GetContext() != "" {
+		spec.context = cfg.GetContext()
+	}
+	if cfg.GetContainerfile() != "" {
+		spec.containerfile = cfg.GetContainerfile()
+	}
+	maps.Copy(spec.args, cfg.GetBuildArgs())
+	if cfg.GetTarget() != "" {
+		spec.target = cfg.GetTarget()
+	}
+	spec.platforms = cfg.GetPlatforms()
+	spec.emulatorImage = cfg.GetEmulatorImage()
+
+	if spec.context == "" {
+		spec.context = "."
+	}
+	if spec.containerfile == "" {
+		spec.containerfile = filepath.Join(spec.context, "Containerfile")
+		if _, err := os.Stat(spec.containerfile); err != nil {
+			spec.containerfile = filepath.Join(spec.context, "Dockerfile")
+		}
+	}
+
+	if _, err := os.Stat(spec.containerfile); err != nil {
+		return buildSpec{}, fmt.Errorf("finding Containerfile to build: %w", err)
+	}
+
+	return spec, nil
+}
+
+// flags returns the flags for a build of spec that are common to all builders, which is all of them
+// except for the tags.
+func (spec buildSpec) flags() []string {
+	out := []string{"--file", spec.containerfile}
+	if len(spec.platforms) > 0 {
+		out = append(out, "--platform", strings.Join(spec.platforms, ","))
+	}
+	for _, key := range slices.Sorted(maps.Keys(spec.args)) {
+		out = append(out, "--build-arg", key+"="+spec.args[key])
+	}
+	if spec.target != "" {
+		out = append(out, "--target", spec.target)
+	}
+
+	return out
+}
+
+// tagFlags returns the flags that tag a build of spec with each of its tags.
+func (spec buildSpec) tagFlags() []string {
+	out := make([]string, 0)
+	for _, tag := range spec.tags {
+		out = append(out, "--tag", tag)
+	}
+
+	return out
+}
+
+// composeFile is the part of a Compose file that describes how to build its services' images.
+type composeFile struct {
+	Services map[string]struct {
+		Build *composeBuild `yaml:"build"`
+	} `yaml:"services"`
+}
+
+// composeBuild is a service's `build` section in a Compose file.
+type composeBuild struct {
+	Context    string      `yaml:"context"`
+	Dockerfile string      `yaml:"dockerfile"`
+	Args       composeArgs `yaml:"args"`
+	Target     string      `yaml:"target"`
+}
+
+// UnmarshalYAML implements [yaml.Unmarshaler], since a `build` section can also be just the context
+// directory.
+func (b *composeBuild) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		b.Context = node.Value
+		return nil
+	}
+
+	type plain composeBuild
+	return node.Decode((*plain)(b))
+}
+
+// composeArgs are the build args of a `build` section in a Compose file.
+type composeArgs map[string]string
+
+// UnmarshalYAML implements [yaml.Unmarshaler], since build args can be either a map, or a list of
+// "KEY=value" strings.
+func (a *composeArgs) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return node.Decode((*map[string]string)(a))
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*a = make(composeArgs)
+	for _, arg := range list {
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			// Compose takes a bare key's value from the environment
+			value = os.Getenv(key)
+		}
+		(*a)[key] = value
+	}
+
+	return nil
+}
+
+// buildSpecFromCompose returns the [buildSpec] for the provided service in a Compose file. Like
+// Compose itself, its context is relative to the Compose file, its Containerfile is relative to its
+// context, and `${VAR}`-style variables in its values are replaced from the environment.
+func buildSpecFromCompose(path string, service string) (buildSpec, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return buildSpec{}, fmt.Errorf("reading Compose file: %w", err)
+	}
+
+	var file composeFile
+	if err := yaml.Unmarshal(contents, &file); err != nil {
+		return buildSpec{}, fmt.Errorf("parsing Compose file '%s': %w", path, err)
+	}
+
+	svc, ok := file.Services[service]
+	if !ok {
+		return buildSpec{}, fmt.Errorf(
+			"service '%s' not found in Compose file '%s' (found: %s)",
+			service, path, strings.Join(slices.Sorted(maps.Keys(file.Services)), ", "),
+		)
+	}
+	if svc.Build == nil {
+		return buildSpec{}, fmt.Errorf("service '%s' in Compose file '%s' has no `build` section", service, path)
+	}
+
+	var errs error
+	expand := func(value string) string {
+		out, err := expandComposeVars(value)
+		errs = errors.Join(errs, err)
+		return out
+	}
+
+	spec := buildSpec{
+		context: filepath.Join(filepath.Dir(path), expand(svc.Build.Context)),
+		args:    make(map[string]string),
+		target:  expand(svc.Build.Target),
+	}
+	if dockerfile := expand(svc.Build.Dockerfile); dockerfile != "" {
+		spec.containerfile = dockerfile
+		if !filepath.IsAbs(dockerfile) {
+			spec.containerfile = filepath.Join(spec.context, dockerfile)
+		}
+	}
+	for key, value := range svc.Build.Args {
+		spec.args[key] = expand(value)
+	}
+	if errs != nil {
+		return buildSpec{}, fmt.Errorf("service '%s' in Compose file '%s': %w", service, path, errs)
+	}
+
+	return spec, nil
+}
+
+// expandComposeVars replaces the variables in a Compose file value from the environment, supporting
+// the `${VAR:-default}`, `${VAR-default}`, `${VAR:?error}`, and `${VAR?error}` forms, and `$$` for a
+// literal `$`.
+func expandComposeVars(value string) (string, error) {
+	var errs error
+	out := os.Expand(value, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+
+		if i := strings.IndexAny(name, ":-?"); i != -1 {
+			key, op := name[:i], name[i:]
+			envValue, set := os.LookupEnv(key)
+			unset := !set || (strings.HasPrefix(op, ":") && envValue == "")
+			op = strings.TrimPrefix(op, ":")
+
+			switch {
+			case !unset:
+				return envValue
+			case strings.HasPrefix(op, "-"):
+				return op[1:]
+			case strings.HasPrefix(op, "?"):
+				errs = errors.Join(errs, fmt.Errorf("variable '%s' must be set: %s", key, op[1:]))
+				return ""
+			}
+		}
+
+		return os.Getenv(name)
+	})
+
+	return out, errs
+}
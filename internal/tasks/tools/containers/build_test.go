@@ -0,0 +1,201 @@
+package containertools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBuildSpec(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll("build", 0755))
+	for _, file := range []string{"Containerfile", "build/Dockerfile", "build/Release.containerfile"} {
+		require.NoError(t, os.WriteFile(file, []byte("FROM scratch\n"), 0644))
+	}
+	require.NoError(t, os.WriteFile("docker-compose.yaml", []byte(`
+services:
+  api:
+    build:
+      context: "./build"
+      dockerfile: "Release.containerfile"
+      args:
+        GO_VERSION: "${OSCAR_TEST_GO_VERSION:-1.25.0}"
+        FROM_ENV: "${OSCAR_TEST_FROM_ENV}"
+      target: "release"
+  short:
+    build: "./build"
+  list-args:
+    build:
+      context: "."
+      args:
+        - "A=1"
+        - "OSCAR_TEST_FROM_ENV"
+  no-build:
+    image: "postgres"
+`), 0644))
+	t.Setenv("OSCAR_TEST_FROM_ENV", "env")
+
+	tt := []struct {
+		Name    string
+		Cfg     *oscarcfgpbv1.ContainerImage
+		Want    buildSpec
+		WantErr bool
+	}{
+		{
+			Name: "defaults",
+			Cfg:  &oscarcfgpbv1.ContainerImage{Name: "api"},
+			Want: buildSpec{containerfile: "Containerfile", context: ".", args: map[string]string{}},
+		},
+		{
+			Name: "falls back to Dockerfile",
+			Cfg:  &oscarcfgpbv1.ContainerImage{Name: "api", Context: "build"},
+			Want: buildSpec{containerfile: "build/Dockerfile", context: "build", args: map[string]string{}},
+		},
+		{
+			Name: "config fields",
+			Cfg: &oscarcfgpbv1.ContainerImage{
+				Name:          "api",
+				Containerfile: "build/Release.containerfile",
+				BuildArgs:     map[string]string{"A": "1"},
+				Target:        "release",
+			},
+			Want: buildSpec{
+				containerfile: "build/Release.containerfile",
+				context:       ".",
+				args:          map[string]string{"A": "1"},
+				target:        "release",
+			},
+		},
+		{
+			Name: "compose",
+			Cfg: &oscarcfgpbv1.ContainerImage{
+				Name:    "api",
+				Compose: &oscarcfgpbv1.ComposeSource{File: "docker-compose.yaml"},
+			},
+			Want: buildSpec{
+				containerfile: "build/Release.containerfile",
+				context:       "build",
+				args:          map[string]string{"GO_VERSION": "1.25.0", "FROM_ENV": "env"},
+				target:        "release",
+			},
+		},
+		{
+			Name: "config fields override compose",
+			Cfg: &oscarcfgpbv1.ContainerImage{
+				Name:      "api",
+				BuildArgs: map[string]string{"GO_VERSION": "1.24.0"},
+				Target:    "debug",
+				Compose:   &oscarcfgpbv1.ComposeSource{File: "docker-compose.yaml"},
+			},
+			Want: buildSpec{
+				containerfile: "build/Release.containerfile",
+				context:       "build",
+				args:          map[string]string{"GO_VERSION": "1.24.0", "FROM_ENV": "env"},
+				target:        "debug",
+			},
+		},
+		{
+			Name: "compose short build & named service",
+			Cfg: &oscarcfgpbv1.ContainerImage{
+				Name:    "api",
+				Compose: &oscarcfgpbv1.ComposeSource{File: "docker-compose.yaml", Service: "short"},
+			},
+			Want: buildSpec{containerfile: "build/Dockerfile", context: "build", args: map[string]string{}},
+		},
+		{
+			Name: "compose list args",
+			Cfg: &oscarcfgpbv1.ContainerImage{
+				Name:    "list-args",
+				Compose: &oscarcfgpbv1.ComposeSource{File: "docker-compose.yaml"},
+			},
+			Want: buildSpec{
+				containerfile: "Containerfile",
+				context:       ".",
+				args:          map[string]string{"A": "1", "OSCAR_TEST_FROM_ENV": "env"},
+			},
+		},
+		{
+			Name:    "missing compose service",
+			Cfg:     &oscarcfgpbv1.ContainerImage{Name: "web", Compose: &oscarcfgpbv1.ComposeSource{File: "docker-compose.yaml"}},
+			WantErr: true,
+		},
+		{
+			Name:    "compose service without build",
+			Cfg:     &oscarcfgpbv1.ContainerImage{Name: "no-build", Compose: &oscarcfgpbv1.ComposeSource{File: "docker-compose.yaml"}},
+			WantErr: true,
+		},
+		{
+			Name:    "missing Containerfile",
+			Cfg:     &oscarcfgpbv1.ContainerImage{Name: "api", Containerfile: "nope"},
+			WantErr: true,
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, err := newBuildSpec(s.Cfg)
+			if s.WantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+}
+
+func TestExpandComposeVars(t *testing.T) {
+	t.Setenv("OSCAR_TEST_SET", "set")
+	t.Setenv("OSCAR_TEST_EMPTY", "")
+
+	tt := []struct {
+		Value   string
+		Want    string
+		WantErr bool
+	}{
+		{Value: "plain", Want: "plain"},
+		{Value: "${OSCAR_TEST_SET}-$OSCAR_TEST_SET", Want: "set-set"},
+		{Value: "${OSCAR_TEST_UNSET:-default}", Want: "default"},
+		{Value: "${OSCAR_TEST_EMPTY:-default}", Want: "default"},
+		{Value: "${OSCAR_TEST_EMPTY-default}", Want: ""},
+		{Value: "${OSCAR_TEST_SET:-default}", Want: "set"},
+		{Value: "$$OSCAR_TEST_SET", Want: "$OSCAR_TEST_SET"},
+		{Value: "${OSCAR_TEST_UNSET:?must be set}", WantErr: true},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Value, func(t *testing.T) {
+			got, err := expandComposeVars(s.Value)
+			if s.WantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+}
+
+func TestBuildSpecFlags(t *testing.T) {
+	spec := buildSpec{
+		containerfile: filepath.Join("build", "Containerfile"),
+		context:       ".",
+		args:          map[string]string{"B": "2", "A": "1"},
+		target:        "release",
+		platforms:     []string{"linux/amd64", "linux/arm64"},
+		tags:          []string{"ghcr.io/a/b:1.0.0", "ghcr.io/a/b:latest"},
+	}
+
+	assert.Equal(t, []string{
+		"--file", "build/Containerfile",
+		"--platform", "linux/amd64,linux/arm64",
+		"--build-arg", "A=1",
+		"--build-arg", "B=2",
+		"--target", "release",
+	}, spec.flags())
+	assert.Equal(t, []string{"--tag", "ghcr.io/a/b:1.0.0", "--tag", "ghcr.io/a/b:latest"}, spec.tagFlags())
+}
@@ -0,0 +1,235 @@
+package containertools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+// The builders that can be set in the `builder` field of a container image's config.
+const (
+	builderDocker  = "docker"
+	builderPodman  = "podman"
+	builderBuildah = "buildah"
+)
+
+// imageBuilder builds container images & pushes them to a registry.
+type imageBuilder interface {
+	// command returns the CLI that the builder runs, which is also used to log in to registries.
+	command() string
+	// buildPush builds the image described by spec, and pushes it to each of its tags.
+	buildPush(ctx context.Context, spec buildSpec) error
+}
+
+// newImageBuilder returns the [imageBuilder] with the provided name, defaulting to Docker.
+func newImageBuilder(name string) (imageBuilder, error) {
+	switch name {
+	case "", builderDocker:
+		return buildxBuilder{}, nil
+	case builderPodman, builderBuildah:
+		return buildahBuilder{cmd: name}, nil
+	default:
+		return nil, fmt.Errorf("unknown image builder '%s'", name)
+	}
+}
+
+// buildxBuilder builds & pushes images in one step via `docker buildx`.
+type buildxBuilder struct{}
+
+// multiPlatformBuilderName is the name of the buildx builder that oscar creates for multi-platform
+// builds, since Docker's default builder can't always build for, or push, more than one platform.
+// It's a var so that tests can use their own builder, rather than one a user already has.
+var multiPlatformBuilderName = "oscar-multiplatform"
+
+// command implements [imageBuilder.command].
+func (b buildxBuilder) command() string { return builderDocker }
+
+// buildPush implements [imageBuilder.buildPush].
+func (b buildxBuilder) buildPush(ctx context.Context, spec buildSpec) error {
+	args := []string{"docker", "buildx", "build"}
+	if len(spec.platforms) > 0 {
+		if err := b.setUpMultiPlatform(ctx, spec.platforms, spec.emulatorImage); err != nil {
+			return fmt.Errorf("setting up multi-platform build: %w", err)
+		}
+		args = append(args, "--builder", multiPlatformBuilderName)
+	}
+	args = slices.Concat(args, spec.flags(), spec.tagFlags(), []string{"--push", spec.context})
+
+	// Container runtimes come from the host, not mise
+	if _, err := system.RunHostCommand(ctx, args); err != nil {
+		return fmt.Errorf("building image: %w", err)
+	}
+
+	return nil
+}
+
+// setUpMultiPlatform creates oscar's multi-platform buildx builder if it doesn't exist yet. If an
+// emulatorImage is provided, it's run to install QEMU emulators for any of the provided platforms
+// that the builder can't build for yet. Otherwise, those platforms are only warned about, since
+// builds for them only fail if they need to run anything.
+func (b buildxBuilder) setUpMultiPlatform(ctx context.Context, platforms []string, emulatorImage string) error {
+	inspectArgs := []string{"docker", "buildx", "inspect", "--bootstrap", multiPlatformBuilderName}
+
+	if _, err := system.RunHostCommand(ctx, []string{"docker", "buildx", "inspect", multiPlatformBuilderName}); err != nil {
+		if _, err := system.RunHostCommand(ctx, []string{
+			"docker", "buildx", "create",
+			"--name", multiPlatformBuilderName,
+			"--driver", "docker-container",
+			// So that it can push to registries on the host's network, like a local one
+			"--driver-opt", "network=host",
+		}); err != nil {
+			return err
+		}
+	}
+
+	output, err := system.RunHostCommand(ctx, inspectArgs)
+	if err != nil {
+		return err
+	}
+	missing := missingPlatforms(parseBuildxPlatforms(output), platforms)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	archs := make([]string, 0)
+	for _, platform := range missing {
+		if parts := strings.Split(platform, "/"); !slices.Contains(archs, parts[1]) {
+			archs = append(archs, parts[1])
+		}
+	}
+	if emulatorImage == "" {
+		iprint.Warnf(
+			"builder can't run commands for %v, so any build steps that do will fail -- install QEMU "+
+				"emulators on the host (e.g. `docker run --privileged --rm tonistiigi/binfmt@sha256:<digest> "+
+				"--install %s`), or set `emulator_image` in the container image config to have oscar do it\n",
+			missing, strings.Join(archs, ","),
+		)
+		return nil
+	}
+
+	if _, err := system.RunHostCommand(ctx, []string{
+		"docker", "run", "--privileged", "--rm", emulatorImage, "--install", strings.Join(archs, ","),
+	}); err != nil {
+		return fmt.Errorf("installing QEMU emulators: %w", err)
+	}
+
+	// The builder only picks up new emulators when it restarts
+	if _, err := system.RunHostCommand(ctx, []string{"docker", "buildx", "stop", multiPlatformBuilderName}); err != nil {
+		return err
+	}
+	output, err = system.RunHostCommand(ctx, inspectArgs)
+	if err != nil {
+		return err
+	}
+	if missing := missingPlatforms(parseBuildxPlatforms(output), platforms); len(missing) > 0 {
+		return fmt.Errorf("builder still can't build for %v after installing QEMU emulators", missing)
+	}
+
+	return nil
+}
+
+// parseBuildxPlatforms returns the platforms that a builder can build for, from the output of
+// `docker buildx inspect`.
+func parseBuildxPlatforms(output string) []string {
+	out := make([]string, 0)
+	for line := range strings.Lines(output) {
+		list, found := strings.CutPrefix(strings.TrimSpace(line), "Platforms:")
+		if !found {
+			continue
+		}
+		for platform := range strings.SplitSeq(list, ",") {
+			// Platforms that the builder was explicitly configured with are marked with a "*"
+			platform = strings.TrimSuffix(strings.TrimSpace(platform), "*")
+			if platform != "" && !slices.Contains(out, platform) {
+				out = append(out, platform)
+			}
+		}
+	}
+
+	return out
+}
+
+// missingPlatforms returns the wanted platforms that aren't in available.
+func missingPlatforms(available []string, wanted []string) []string {
+	out := make([]string, 0)
+	for _, platform := range wanted {
+		if !slices.Contains(available, platform) {
+			out = append(out, platform)
+		}
+	}
+
+	return out
+}
+
+// buildahBuilder builds images with Buildah, or with Podman (which uses Buildah under the hood, and
+// takes the same flags), and then pushes each tag. Builds for multiple platforms are collected into a
+// manifest list, which is pushed instead, and need QEMU emulators (e.g. from the host's
+// `qemu-user-static` package) for platforms other than the host's.
+type buildahBuilder struct {
+	// Either "buildah" or "podman".
+	cmd string
+}
+
+// command implements [imageBuilder.command].
+func (b buildahBuilder) command() string { return b.cmd }
+
+// buildPush implements [imageBuilder.buildPush].
+func (b buildahBuilder) buildPush(ctx context.Context, spec buildSpec) error {
+	if len(spec.platforms) > 0 {
+		return b.buildPushManifest(ctx, spec)
+	}
+
+	args := slices.Concat(
+		[]string{b.cmd, "build"},
+		spec.flags(),
+		spec.tagFlags(),
+		[]string{spec.context},
+	)
+	if _, err := system.RunHostCommand(ctx, args); err != nil {
+		return fmt.Errorf("building image: %w", err)
+	}
+
+	for _, tag := range spec.tags {
+		if _, err := system.RunHostCommand(ctx, []string{b.cmd, "push", tag}); err != nil {
+			return fmt.Errorf("pushing image '%s': %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// buildPushManifest builds spec for each of its platforms into a single manifest list, and pushes
+// it to each tag.
+func (b buildahBuilder) buildPushManifest(ctx context.Context, spec buildSpec) error {
+	if len(spec.tags) == 0 {
+		return errors.New("internal error: no tags to push a multi-platform image to")
+	}
+	manifest := spec.tags[0]
+
+	// A manifest list left over from an earlier build would have its images added to, so start over
+	_, _ = system.RunHostCommand(ctx, []string{b.cmd, "manifest", "rm", manifest})
+
+	args := slices.Concat(
+		[]string{b.cmd, "build"},
+		spec.flags(),
+		[]string{"--manifest", manifest, spec.context},
+	)
+	if _, err := system.RunHostCommand(ctx, args); err != nil {
+		return fmt.Errorf("building image: %w", err)
+	}
+
+	for _, tag := range spec.tags {
+		if _, err := system.RunHostCommand(ctx, []string{
+			b.cmd, "manifest", "push", "--all", manifest, "docker://" + tag,
+		}); err != nil {
+			return fmt.Errorf("pushing image '%s': %w", tag, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package containertools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBuildxPlatforms(t *testing.T) {
+	output := `Name:          oscar-multiplatform
+Driver:        docker-container
+
+Nodes:
+Name:                  oscar-multiplatform0
+Status:                running
+Platforms:             linux/amd64*, linux/amd64/v2, linux/386
+Labels:
+ org.mobyproject.buildkit.worker.executor: oci
+
+Name:                  oscar-multiplatform1
+Platforms:             linux/arm64, linux/amd64
+`
+
+	got := parseBuildxPlatforms(output)
+	assert.Equal(t, []string{"linux/amd64", "linux/amd64/v2", "linux/386", "linux/arm64"}, got)
+	assert.Equal(t, []string{"linux/arm/v7"}, missingPlatforms(got, []string{"linux/arm64", "linux/arm/v7"}))
+}
+
+// Credentials for the registries started by [startTestRegistry] with auth.
+const (
+	testRegistryUsername = "oscar"
+	testRegistryPassword = "oscar-test-password"
+)
+
+// startTestRegistry starts a local `registry:2` container that's removed when the test ends, and
+// returns its address. If withAuth is set, the registry needs a login with [testRegistryUsername] &
+// [testRegistryPassword]. The test is skipped if Docker isn't available.
+func startTestRegistry(t *testing.T, withAuth bool) string {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker not found")
+	}
+	if _, err := system.RunHostCommand(ctx, []string{"docker", "info"}); err != nil {
+		t.Skipf("docker not available: %v", err)
+	}
+
+	args := []string{"docker", "run", "--detach", "--rm", "--publish", "127.0.0.1::5000"}
+	if withAuth {
+		htpasswd, err := system.RunHostCommand(ctx, []string{
+			"docker", "run", "--rm", "--entrypoint", "htpasswd", "httpd:2",
+			"-Bbn", testRegistryUsername, testRegistryPassword,
+		})
+		require.NoError(t, err)
+		authDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(authDir, "htpasswd"), []byte(htpasswd+"\n"), 0644))
+
+		args = append(args,
+			"--volume", authDir+":/auth:ro",
+			"--env", "REGISTRY_AUTH=htpasswd",
+			"--env", "REGISTRY_AUTH_HTPASSWD_REALM=oscar-test",
+			"--env", "REGISTRY_AUTH_HTPASSWD_PATH=/auth/htpasswd",
+		)
+	}
+
+	id, err := system.RunHostCommand(ctx, append(args, "registry:2"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = system.RunHostCommand(ctx, []string{"docker", "rm", "--force", id})
+	})
+
+	hostPort, err := system.RunHostCommand(ctx, []string{"docker", "port", id, "5000/tcp"})
+	require.NoError(t, err)
+	_, port, found := strings.Cut(strings.Split(hostPort, "\n")[0], "127.0.0.1:")
+	require.True(t, found, "unexpected port mapping '%s'", hostPort)
+	// Container runtimes only allow plain HTTP to registries on "localhost"
+	registry := "localhost:" + port
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + registry + "/v2/")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return true
+	}, 30*time.Second, 250*time.Millisecond, "registry never became ready")
+
+	return registry
+}
+
+// TestBuildPushMultiPlatformLocalRegistry builds & pushes a multi-platform image to a local
+// registry, and checks the index that was pushed. The image doesn't run anything, so it doesn't need
+// any QEMU emulators.
+func TestBuildPushMultiPlatformLocalRegistry(t *testing.T) {
+	registry := startTestRegistry(t, false)
+	ctx := context.Background()
+
+	// Never touch the builder that real runs use, or its cache
+	realBuilderName := multiPlatformBuilderName
+	multiPlatformBuilderName = "oscar-multiplatform-test"
+	t.Cleanup(func() {
+		_, _ = system.RunHostCommand(ctx, []string{"docker", "buildx", "rm", multiPlatformBuilderName})
+		multiPlatformBuilderName = realBuilderName
+	})
+
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("hello.txt", []byte("hello\n"), 0644))
+	require.NoError(t, os.WriteFile("Containerfile", []byte("FROM scratch\nCOPY hello.txt /\n"), 0644))
+
+	platforms := []string{"linux/amd64", "linux/arm64"}
+	spec, err := newBuildSpec(&oscarcfgpbv1.ContainerImage{Name: "multiplatform", Platforms: platforms})
+	require.NoError(t, err)
+	spec.tags = []string{registry + "/oscar-test/multiplatform:test"}
+
+	require.NoError(t, buildxBuilder{}.buildPush(ctx, spec))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+registry+"/v2/oscar-test/multiplatform/manifests/test", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", strings.Join([]string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+	}, ", "))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var index struct {
+		Manifests []struct {
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&index))
+
+	got := make([]string, 0)
+	for _, manifest := range index.Manifests {
+		// buildx also pushes attestation manifests, which have an "unknown" platform
+		if manifest.Platform.OS != "unknown" {
+			got = append(got, manifest.Platform.OS+"/"+manifest.Platform.Architecture)
+		}
+	}
+	assert.ElementsMatch(t, platforms, got)
+}
@@ -2,9 +2,9 @@ package containertools
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/opensourcecorp/oscar/internal/system"
 	"github.com/opensourcecorp/oscar/internal/tasks/tools/toolcfg"
@@ -21,12 +21,10 @@ func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
 		return []taskutil.Tasker{
 			hadolint{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"bash", "-c",
-						fmt.Sprintf(
-							`hadolint --config {{ConfigFilePath}} $(%s)`,
-							system.GetFileTypeListerCommand("containerfile"),
-						),
-					},
+					RunArgs: slices.Concat(
+						[]string{"hadolint", "--config", "{{ConfigFilePath}}"},
+						repo.FilesOfType("containerfile"),
+					),
 					ConfigFilePath: filepath.Join(os.TempDir(), "hadolint.yaml"),
 				},
 			},
@@ -41,7 +39,7 @@ func (t hadolint) InfoText() string { return "Lint (hadolint)" }
 
 // Run implements [taskutil.Tasker.Run].
 func (t hadolint) Exec(ctx context.Context) error {
-	if err := toolcfg.SetupConfigFile(t.Tool); err != nil {
+	if err := toolcfg.SetupConfigFile(ctx, t.Tool); err != nil {
 		return err
 	}
 
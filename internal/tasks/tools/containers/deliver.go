@@ -3,44 +3,16 @@ package containertools
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
+	"time"
 
-	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
-	igit "github.com/opensourcecorp/oscar/internal/git"
 	"github.com/opensourcecorp/oscar/internal/oscarcfg"
-	iprint "github.com/opensourcecorp/oscar/internal/print"
-	"github.com/opensourcecorp/oscar/internal/system"
 	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
-	"go.yaml.in/yaml/v4"
 )
 
-type (
-	imageBuildPush struct{ taskutil.Tool }
-)
-
-// registryMapping contains substructs to be used based on the target OCI registry.
-type registryMapping struct {
-	GitHub gitHubRegistry
-}
-
-// gitHubRegistry provides fields for use in targeting "ghcr.io".
-type gitHubRegistry struct {
-	// The command to run to authenticate to the registry.
-	AuthCommand []string
-}
-
-// newRegistryMap returns a populated [registryMapping].
-func newRegistryMap(username string) registryMapping {
-	return registryMapping{
-		GitHub: gitHubRegistry{
-			AuthCommand: []string{"bash", "-c", fmt.Sprintf(`
-				echo ${GITHUB_TOKEN} | docker login ghcr.io --username %s --password-stdin
-				`, username,
-			)},
-		},
-	}
+type imageBuildPush struct {
+	taskutil.Tool
+	// What to build, without any tags yet.
+	spec buildSpec
 }
 
 // NewTasksForDelivery returns the list of CI tasks.
@@ -53,8 +25,20 @@ func NewTasksForDelivery(repo taskutil.Repo) ([]taskutil.Tasker, error) {
 	if repo.HasContainerfile {
 		out := make([]taskutil.Tasker, 0)
 
-		if cfg.GetDeliverables().GetContainerImage() != nil {
-			out = append(out, imageBuildPush{})
+		if imageCfg := cfg.GetDeliverables().GetContainerImage(); imageCfg != nil {
+			// Checked up front, so that e.g. a missing Compose service fails before anything runs
+			spec, err := newBuildSpec(imageCfg)
+			if err != nil {
+				return nil, fmt.Errorf("container image config: %w", err)
+			}
+
+			out = append(out, imageBuildPush{
+				Tool: taskutil.Tool{
+					// Image builds & pushes can take a while
+					Timeout: 30 * time.Minute,
+				},
+				spec: spec,
+			})
 		}
 
 		return out, nil
@@ -74,63 +58,27 @@ func (t imageBuildPush) Exec(ctx context.Context) error {
 	}
 	cfg := rootCfg.GetDeliverables().GetContainerImage()
 
-	uri, err := constructImageURI(ctx, rootCfg)
-	if err != nil {
-		return fmt.Errorf("constructing image URI: %w", err)
-	}
-
-	composeFileContents, err := os.ReadFile("docker-compose.yaml")
+	uris, err := constructImageURIs(ctx, rootCfg)
 	if err != nil {
-		return err
-	}
-
-	composeFile := make(map[string]any)
-	if err := yaml.Unmarshal(composeFileContents, composeFile); err != nil {
-		return err
+		return fmt.Errorf("constructing image URIs: %w", err)
 	}
-	iprint.Debugf("composeFile unmarshalled: %#v\n", composeFile)
+	spec := t.spec
+	spec.tags = uris
 
-	curDir, err := os.Getwd()
+	builder, err := newImageBuilder(cfg.GetBuilder())
 	if err != nil {
 		return err
 	}
 
-	// GROSS, DUDE
-	composeFile["services"].(map[string]any)[cfg.GetName()].(map[string]any)["image"] = uri
-	composeFile["services"].(map[string]any)[cfg.GetName()].(map[string]any)["build"].(map[string]any)["context"] = curDir
-
-	composeOut, err := yaml.Marshal(composeFile)
+	auth, err := newRegistryAuth(cfg)
 	if err != nil {
 		return err
 	}
-	iprint.Debugf("edited Compose file YAML: %s\n", string(composeOut))
-
-	workDir := filepath.Join(os.TempDir(), "oscar-oci")
-	if err := os.MkdirAll(workDir, 0755); err != nil {
-		return err
-	}
-
-	outPath := filepath.Join(workDir, "docker-compose.yaml")
-	if err := os.WriteFile(outPath, composeOut, 0644); err != nil {
+	if err := auth.login(ctx, builder.command(), cfg.GetRegistry()); err != nil {
 		return err
 	}
 
-	registryMap := newRegistryMap(cfg.GetName())
-
-	var authArgs []string
-	if strings.Contains(cfg.Registry, "ghcr") {
-		authArgs = registryMap.GitHub.AuthCommand
-	}
-
-	if _, err := system.RunCommand(ctx, authArgs); err != nil {
-		return err
-	}
-
-	buildPushArgs := []string{"bash", "-c", fmt.Sprintf(`
-		docker compose --file %s build --push %s
-		`, outPath, cfg.GetName(),
-	)}
-	if _, err := system.RunCommand(ctx, buildPushArgs); err != nil {
+	if err := builder.buildPush(ctx, spec); err != nil {
 		return err
 	}
 
@@ -139,34 +87,3 @@ func (t imageBuildPush) Exec(ctx context.Context) error {
 
 // Post implements [taskutil.Tasker.Post].
 func (t imageBuildPush) Post(_ context.Context) error { return nil }
-
-// constructImageURI constructs an image URI based on data from oscar's config & Git.
-func constructImageURI(ctx context.Context, rootCfg *oscarcfgpbv1.Config) (string, error) {
-	cfg := rootCfg.GetDeliverables().GetContainerImage()
-	git, err := igit.New(ctx)
-	if err != nil {
-		return "", fmt.Errorf("getting Git info: %w", err)
-	}
-
-	tag := rootCfg.GetVersion()
-
-	if git.Branch == "main" {
-		// NOTE: this might be a risky hack, but because the containerized tests copy other language
-		// files to the root directory and rename them, the git diff will be nonzero, and the tag
-		// value will be impossible to check against. So, override that here.
-		git.IsDirty = false
-	} else {
-		tag = fmt.Sprintf("%s-%s", git.SanitizedBranch(), git.LatestCommit)
-	}
-	if git.IsDirty {
-		tag = fmt.Sprintf("%s-%s-dirty", git.SanitizedBranch(), git.LatestCommit)
-	}
-
-	uri := fmt.Sprintf(
-		"%s/%s/%s:%s",
-		cfg.GetRegistry(), cfg.GetNamespace(), cfg.GetName(), tag,
-	)
-	iprint.Debugf("image URI: %s\n", uri)
-
-	return uri, nil
-}
@@ -0,0 +1,127 @@
+package containertools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	"github.com/opensourcecorp/oscar/internal/oscarcfg"
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"golang.org/x/mod/semver"
+)
+
+// The kinds of tags that can be listed in a container image's tag policy.
+const (
+	tagVersion      = "version"
+	tagMajorMinor   = "major_minor"
+	tagLatest       = "latest"
+	tagCommit       = "commit"
+	tagBranch       = "branch"
+	tagBranchCommit = "branch_commit"
+)
+
+// Default tag kinds, if the tag policy doesn't list any.
+var (
+	defaultReleaseTags = []string{tagVersion}
+	defaultOtherTags   = []string{tagBranchCommit}
+)
+
+// constructImageURIs constructs the URIs that an image is pushed to, one per tag, based on data
+// from oscar's config & Git.
+func constructImageURIs(ctx context.Context, rootCfg *oscarcfgpbv1.Config) ([]string, error) {
+	cfg := rootCfg.GetDeliverables().GetContainerImage()
+	git, err := igit.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting Git info: %w", err)
+	}
+
+	tags, err := imageTags(rootCfg, git)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		out = append(out, fmt.Sprintf("%s/%s/%s:%s", cfg.GetRegistry(), cfg.GetNamespace(), cfg.GetName(), tag))
+	}
+	iprint.Debugf("image URIs: %v\n", out)
+
+	return out, nil
+}
+
+// imageTags returns the tags that an image should be pushed with, per the container image's tag
+// policy (see [oscarcfgpbv1.TagPolicy]).
+func imageTags(rootCfg *oscarcfgpbv1.Config, git *igit.Git) ([]string, error) {
+	policy := rootCfg.GetDeliverables().GetContainerImage().GetTags()
+
+	releaseBranch := policy.GetReleaseBranch()
+	if releaseBranch == "" {
+		releaseBranch = oscarcfg.BaseBranch(rootCfg)
+	}
+
+	kinds := policy.GetOther()
+	if len(kinds) == 0 {
+		kinds = defaultOtherTags
+	}
+	if git.Branch == releaseBranch {
+		kinds = policy.GetRelease()
+		if len(kinds) == 0 {
+			kinds = defaultReleaseTags
+		}
+	}
+
+	version := rootCfg.GetVersion()
+	stable := semver.Prerelease("v"+version) == ""
+
+	dirtySuffix := ""
+	if git.IsDirty {
+		dirtySuffix = "-dirty"
+	}
+
+	out := make([]string, 0)
+	var errs error
+	for _, kind := range kinds {
+		var tag string
+		switch kind {
+		case tagVersion:
+			// Build metadata's "+" isn't allowed in image tags
+			tag = strings.ReplaceAll(version, "+", "-")
+		case tagMajorMinor:
+			if stable {
+				tag = strings.TrimPrefix(semver.MajorMinor("v"+version), "v")
+			}
+		case tagLatest:
+			if stable {
+				tag = tagLatest
+			}
+		case tagCommit:
+			tag = git.LatestCommit + dirtySuffix
+		case tagBranch:
+			tag = git.SanitizedBranch() + dirtySuffix
+		case tagBranchCommit:
+			tag = git.SanitizedBranch() + "-" + git.LatestCommit + dirtySuffix
+		default:
+			errs = errors.Join(errs, fmt.Errorf("unknown image tag kind '%s'", kind))
+		}
+
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	if len(out) == 0 {
+		return nil, fmt.Errorf(
+			"no image tags apply to version '%s' on branch '%s' (tags configured: %v)",
+			version, git.Branch, kinds,
+		)
+	}
+
+	return out, nil
+}
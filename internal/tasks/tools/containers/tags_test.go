@@ -0,0 +1,123 @@
+package containertools
+
+import (
+	"context"
+	"testing"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	"github.com/opensourcecorp/oscar/internal/oscarcfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructImageURIs(t *testing.T) {
+	cfg, err := oscarcfg.Get("../../../oscarcfg/test.oscar.yaml")
+	require.NoError(t, err)
+
+	got, err := constructImageURIs(context.Background(), cfg)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, got)
+	for _, uri := range got {
+		assert.Regexp(t, `^ghcr\.io/opensourcecorp/oscar:[A-Za-z0-9_.-]+$`, uri)
+	}
+}
+
+func TestImageTags(t *testing.T) {
+	tt := []struct {
+		Name    string
+		Version string
+		Policy  *oscarcfgpbv1.TagPolicy
+		Git     igit.Git
+		Want    []string
+		WantErr bool
+	}{
+		{
+			Name:    "default on base branch",
+			Version: "1.2.3",
+			Git:     igit.Git{Branch: "main", LatestCommit: "a1b2c3d"},
+			Want:    []string{"1.2.3"},
+		},
+		{
+			Name:    "default on other branch",
+			Version: "1.2.3",
+			Git:     igit.Git{Branch: "feature/thing", LatestCommit: "a1b2c3d"},
+			Want:    []string{"feature-thing-a1b2c3d"},
+		},
+		{
+			Name:    "version tags are never dirty",
+			Version: "1.2.3",
+			Git:     igit.Git{Branch: "main", LatestCommit: "a1b2c3d", IsDirty: true},
+			Want:    []string{"1.2.3"},
+		},
+		{
+			Name:    "Git tags are dirty",
+			Version: "1.2.3",
+			Policy:  &oscarcfgpbv1.TagPolicy{Other: []string{tagBranch, tagCommit, tagBranchCommit}},
+			Git:     igit.Git{Branch: "feature/thing", LatestCommit: "a1b2c3d", IsDirty: true},
+			Want:    []string{"feature-thing-dirty", "a1b2c3d-dirty", "feature-thing-a1b2c3d-dirty"},
+		},
+		{
+			Name:    "stable release",
+			Version: "1.2.3",
+			Policy:  &oscarcfgpbv1.TagPolicy{Release: []string{tagVersion, tagMajorMinor, tagLatest, tagCommit}},
+			Git:     igit.Git{Branch: "main", LatestCommit: "a1b2c3d"},
+			Want:    []string{"1.2.3", "1.2", "latest", "a1b2c3d"},
+		},
+		{
+			Name:    "prerelease skips stable-only tags",
+			Version: "1.2.3-rc1",
+			Policy:  &oscarcfgpbv1.TagPolicy{Release: []string{tagVersion, tagMajorMinor, tagLatest}},
+			Git:     igit.Git{Branch: "main", LatestCommit: "a1b2c3d"},
+			Want:    []string{"1.2.3-rc1"},
+		},
+		{
+			Name:    "build metadata",
+			Version: "1.2.3+build5",
+			Policy:  &oscarcfgpbv1.TagPolicy{Release: []string{tagVersion, tagMajorMinor}},
+			Git:     igit.Git{Branch: "main", LatestCommit: "a1b2c3d"},
+			Want:    []string{"1.2.3-build5", "1.2"},
+		},
+		{
+			Name:    "custom release branch",
+			Version: "1.2.3",
+			Policy:  &oscarcfgpbv1.TagPolicy{ReleaseBranch: "release"},
+			Git:     igit.Git{Branch: "release", LatestCommit: "a1b2c3d"},
+			Want:    []string{"1.2.3"},
+		},
+		{
+			Name:    "base branch isn't the release branch if another is set",
+			Version: "1.2.3",
+			Policy:  &oscarcfgpbv1.TagPolicy{ReleaseBranch: "release"},
+			Git:     igit.Git{Branch: "main", LatestCommit: "a1b2c3d"},
+			Want:    []string{"main-a1b2c3d"},
+		},
+		{
+			Name:    "no tags apply",
+			Version: "1.2.3-rc1",
+			Policy:  &oscarcfgpbv1.TagPolicy{Release: []string{tagLatest}},
+			Git:     igit.Git{Branch: "main", LatestCommit: "a1b2c3d"},
+			WantErr: true,
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			cfg := &oscarcfgpbv1.Config{
+				Version: s.Version,
+				Deliverables: &oscarcfgpbv1.Deliverables{
+					ContainerImage: &oscarcfgpbv1.ContainerImage{Tags: s.Policy},
+				},
+			}
+
+			got, err := imageTags(cfg, &s.Git)
+			if s.WantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+}
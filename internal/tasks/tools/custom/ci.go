@@ -0,0 +1,113 @@
+package customtools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	"github.com/opensourcecorp/oscar/internal/system"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+)
+
+// DefaultGroup is the group that custom tasks are listed under if they don't set one.
+const DefaultGroup = "Custom"
+
+type customTask struct {
+	taskutil.Tool
+	// The task's name, as set in the oscar config file.
+	name string
+	// Any "KEY=value" environment variables to run the command with.
+	env []string
+}
+
+// NewTasksForCI returns the custom tasks from the oscar config file that apply to the provided
+// project, keyed by their group. Tasks with file patterns only apply to projects with matching
+// files, and tasks without any only apply to the [taskutil.RootProject].
+//
+// Within a group, custom tasks that don't rewrite files wait for any that do, so that e.g. a linter
+// checks what a formatter produced.
+func NewTasksForCI(project taskutil.Project, cfgTasks []*oscarcfgpbv1.CustomTask) (taskutil.TaskMap, error) {
+	out := make(taskutil.TaskMap)
+	mutatingNames := make(map[string][]string)
+	seen := make([]string, 0)
+
+	for _, cfgTask := range cfgTasks {
+		group := cfgTask.GetGroup()
+		if group == "" {
+			group = DefaultGroup
+		}
+
+		if strings.Contains(group, "::") || strings.Contains(cfgTask.GetName(), "::") {
+			return nil, fmt.Errorf("custom task '%s' in group '%s' can't have '::' in its name or group", cfgTask.GetName(), group)
+		}
+		id := group + "::" + cfgTask.GetName()
+		if slices.Contains(seen, id) {
+			return nil, fmt.Errorf("custom task '%s' is defined more than once in group '%s'", cfgTask.GetName(), group)
+		}
+		seen = append(seen, id)
+
+		runArgs := slices.Clone(cfgTask.GetCommand())
+		if len(cfgTask.GetFiles()) > 0 {
+			files, err := project.Repo.FilesMatching(cfgTask.GetFiles())
+			if err != nil {
+				return nil, fmt.Errorf("custom task '%s': %w", id, err)
+			}
+			if len(files) == 0 {
+				continue
+			}
+			runArgs = append(runArgs, files...)
+		} else if project.Root != taskutil.RootProject {
+			continue
+		}
+
+		env := make([]string, 0)
+		for key, value := range cfgTask.GetEnv() {
+			env = append(env, key+"="+value)
+		}
+		slices.Sort(env)
+
+		task := customTask{
+			Tool: taskutil.Tool{
+				RunArgs:  runArgs,
+				Mutating: cfgTask.GetMutating(),
+				// oscar can't know everything that a custom command depends on, like tools that it
+				// doesn't install itself
+				Uncacheable: true,
+			},
+			name: cfgTask.GetName(),
+			env:  env,
+		}
+		if task.Mutating {
+			mutatingNames[group] = append(mutatingNames[group], task.name)
+		}
+		out[group] = append(out[group], task)
+	}
+
+	for group, tasks := range out {
+		for i, task := range tasks {
+			if ct := task.(customTask); !ct.Mutating {
+				ct.DependsOn = mutatingNames[group]
+				tasks[i] = ct
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t customTask) InfoText() string { return t.name }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t customTask) Exec(ctx context.Context) error {
+	if _, err := system.RunCommand(system.WithEnv(ctx, t.env...), t.RenderRunCommandArgs()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t customTask) Post(_ context.Context) error { return nil }
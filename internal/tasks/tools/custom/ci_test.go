@@ -0,0 +1,120 @@
+package customtools
+
+import (
+	"testing"
+
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTasksForCI(t *testing.T) {
+	cfgTasks := []*oscarcfgpbv1.CustomTask{
+		{
+			Name:    "Lint (sqlfluff)",
+			Group:   "SQL",
+			Command: []string{"sqlfluff", "lint"},
+			Files:   []string{"*.sql"},
+			Env:     map[string]string{"B": "2", "A": "1"},
+		},
+		{
+			Name:     "Format (sqlfluff)",
+			Group:    "SQL",
+			Command:  []string{"sqlfluff", "fix"},
+			Files:    []string{"*.sql"},
+			Mutating: true,
+		},
+		{
+			Name:    "Check licenses",
+			Command: []string{"make", "licenses"},
+		},
+		{
+			Name:    "Lint (buf)",
+			Group:   "Protobuf",
+			Command: []string{"buf", "lint"},
+			Files:   []string{"*.proto"},
+		},
+	}
+
+	t.Run("root project", func(t *testing.T) {
+		project := taskutil.Project{
+			Root: taskutil.RootProject,
+			Repo: taskutil.Repo{Files: []string{"main.go", "db/schema.sql"}},
+		}
+
+		got, err := NewTasksForCI(project, cfgTasks)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{DefaultGroup, "SQL"}, got.SortedKeys())
+		require.Len(t, got["SQL"], 2)
+
+		lint := got["SQL"][0].(customTask)
+		assert.Equal(t, "Lint (sqlfluff)", lint.InfoText())
+		assert.Equal(t, []string{"sqlfluff", "lint", "db/schema.sql"}, lint.RunArgs)
+		assert.Equal(t, []string{"A=1", "B=2"}, lint.env)
+		assert.Equal(t, []string{"Format (sqlfluff)"}, lint.DependsOn)
+		assert.True(t, lint.Uncacheable)
+
+		format := got["SQL"][1].(customTask)
+		assert.True(t, format.Mutating)
+		assert.Empty(t, format.DependsOn)
+
+		assert.Equal(t, []string{"make", "licenses"}, got[DefaultGroup][0].ToolInfo().RunArgs)
+	})
+
+	t.Run("nested project", func(t *testing.T) {
+		project := taskutil.Project{
+			Root: "services/api",
+			Repo: taskutil.Repo{Files: []string{"go.mod", "schema.sql"}},
+		}
+
+		got, err := NewTasksForCI(project, cfgTasks)
+		require.NoError(t, err)
+
+		// Tasks without file patterns only run at the repo root
+		assert.Equal(t, []string{"SQL"}, got.SortedKeys())
+	})
+
+	t.Run("changed files only", func(t *testing.T) {
+		project := taskutil.Project{
+			Root: taskutil.RootProject,
+			Repo: taskutil.Repo{Files: []string{"a.sql", "b.sql"}, ChangedFiles: []string{"b.sql"}},
+		}
+
+		got, err := NewTasksForCI(project, cfgTasks)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sqlfluff", "lint", "b.sql"}, got["SQL"][0].ToolInfo().RunArgs)
+	})
+}
+
+func TestNewTasksForCIErrors(t *testing.T) {
+	tt := []struct {
+		Name     string
+		CfgTasks []*oscarcfgpbv1.CustomTask
+	}{
+		{
+			Name: "duplicate",
+			CfgTasks: []*oscarcfgpbv1.CustomTask{
+				{Name: "Lint", Command: []string{"a"}},
+				{Name: "Lint", Group: DefaultGroup, Command: []string{"b"}},
+			},
+		},
+		{
+			Name:     "filter separator in name",
+			CfgTasks: []*oscarcfgpbv1.CustomTask{{Name: "Go::Lint", Command: []string{"a"}}},
+		},
+		{
+			Name:     "empty file pattern",
+			CfgTasks: []*oscarcfgpbv1.CustomTask{{Name: "Lint", Command: []string{"a"}, Files: []string{"/"}}},
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			project := taskutil.Project{Root: taskutil.RootProject, Repo: taskutil.Repo{Files: []string{"a.sql"}}}
+			_, err := NewTasksForCI(project, s.CfgTasks)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package customtools contains logic for running the custom tasks defined in the oscar config file.
+package customtools
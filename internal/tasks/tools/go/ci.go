@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/opensourcecorp/oscar/internal/system"
 	"github.com/opensourcecorp/oscar/internal/tasks/tools/toolcfg"
@@ -12,82 +14,115 @@ import (
 )
 
 type (
-	goModCheck     struct{ taskutil.Tool }
-	goFormat       struct{ taskutil.Tool }
-	generateCodeCI struct{ taskutil.Tool }
-	goBuildCI      struct{ taskutil.Tool }
-	goVet          struct{ taskutil.Tool }
-	staticcheck    struct{ taskutil.Tool }
-	revive         struct{ taskutil.Tool }
-	errcheck       struct{ taskutil.Tool }
-	goImports      struct{ taskutil.Tool }
-	govulncheck    struct{ taskutil.Tool }
-	goTest         struct{ taskutil.Tool }
+	goModCheck struct{ taskutil.Tool }
+	goFormat   struct{ taskutil.Tool }
+	// NOTE: this also holds the project's own formatting Tasks, so that it can rerun them on the same
+	// files after generating code
+	generateCodeCI struct {
+		taskutil.Tool
+		formatters []taskutil.Tasker
+	}
+	goBuildCI   struct{ taskutil.Tool }
+	goVet       struct{ taskutil.Tool }
+	staticcheck struct{ taskutil.Tool }
+	revive      struct{ taskutil.Tool }
+	errcheck    struct{ taskutil.Tool }
+	goImports   struct{ taskutil.Tool }
+	govulncheck struct{ taskutil.Tool }
+	goTest      struct{ taskutil.Tool }
 )
 
 // NewTasksForCI returns the list of CI tasks.
 func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
 	if repo.HasGo {
-		return []taskutil.Tasker{
-			goModCheck{
-				Tool: taskutil.Tool{
-					RunArgs: []string{"go", "mod", "tidy"},
-				},
+		pkgs := repo.GoPackages()
+
+		goImportsTargets := []string{"."}
+		if repo.ChangedOnly() {
+			goImportsTargets = repo.FilesOfType("go")
+		}
+
+		format := goFormat{
+			Tool: taskutil.Tool{
+				RunArgs:  slices.Concat([]string{"go", "fmt"}, pkgs),
+				Mutating: true,
 			},
-			goFormat{
-				Tool: taskutil.Tool{
-					RunArgs: []string{"go", "fmt", "./..."},
-				},
+		}
+		imports := goImports{
+			Tool: taskutil.Tool{
+				RunArgs:   slices.Concat([]string{"goimports", "-l", "-w"}, goImportsTargets),
+				DependsOn: []string{goFormat{}.InfoText()},
+				Mutating:  true,
 			},
-			goImports{
+		}
+
+		return []taskutil.Tasker{
+			goModCheck{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"goimports", "-l", "-w", "."},
+					RunArgs:  []string{"go", "mod", "tidy"},
+					Mutating: true,
 				},
 			},
+			format,
+			imports,
 			generateCodeCI{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"go", "generate", "./..."},
+					RunArgs:   slices.Concat([]string{"go", "generate"}, pkgs),
+					DependsOn: []string{goModCheck{}.InfoText(), goImports{}.InfoText()},
+					Mutating:  true,
 				},
+				formatters: []taskutil.Tasker{format, imports},
 			},
 			goBuildCI{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"go", "build", "./..."},
+					RunArgs:   slices.Concat([]string{"go", "build"}, pkgs),
+					DependsOn: []string{generateCodeCI{}.InfoText()},
 				},
 			},
 			goVet{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"go", "vet", "./..."},
+					RunArgs:   slices.Concat([]string{"go", "vet"}, pkgs),
+					DependsOn: []string{goBuildCI{}.InfoText()},
 				},
 			},
 			staticcheck{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"staticcheck", "./..."},
+					RunArgs: slices.Concat([]string{"staticcheck"}, pkgs),
 					// NOTE: staticcheck does not have a flag to point to a config file, so we need
-					// to put it at the repo root
+					// to put it at the project root
 					ConfigFilePath: filepath.Join("staticcheck.conf"),
+					DependsOn:      []string{goBuildCI{}.InfoText()},
 				},
 			},
 			revive{
 				Tool: taskutil.Tool{
-					RunArgs: []string{
-						"revive", "--config", "{{ConfigFilePath}}", "--set_exit_status", "./...",
-					},
+					RunArgs: slices.Concat(
+						[]string{"revive", "--config", "{{ConfigFilePath}}", "--set_exit_status"},
+						pkgs,
+					),
 					ConfigFilePath: filepath.Join(os.TempDir(), "revive.toml"),
+					DependsOn:      []string{goBuildCI{}.InfoText()},
 				},
 			},
 			errcheck{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"errcheck", "./..."},
+					RunArgs:   slices.Concat([]string{"errcheck"}, pkgs),
+					DependsOn: []string{goBuildCI{}.InfoText()},
 				},
 			},
 			govulncheck{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"govulncheck", "./..."},
+					RunArgs:   slices.Concat([]string{"govulncheck"}, pkgs),
+					DependsOn: []string{goBuildCI{}.InfoText()},
 				},
 			},
 			goTest{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"go", "test", "./..."},
+					RunArgs:   slices.Concat([]string{"go", "test"}, pkgs),
+					DependsOn: []string{goBuildCI{}.InfoText()},
+					// NOTE: `go test` already times out each package's tests on its own, after 10
+					// minutes by default, so this only needs to catch the rest of it hanging
+					Timeout: 30 * time.Minute,
 				},
 			},
 		}
@@ -151,18 +186,10 @@ func (t generateCodeCI) Exec(ctx context.Context) error {
 	}
 
 	// Generating code will likely throw diffs if not also addressing other formatting CI checks, so
-	// run those here again as well.
-	tasks := NewTasksForCI(taskutil.Repo{HasGo: true})
-	for _, task := range tasks {
-		if wantToRun, isOfType := task.(goFormat); isOfType {
-			if err := wantToRun.Exec(ctx); err != nil {
-				return fmt.Errorf("running Go formatter after code generation: %w", err)
-			}
-		}
-		if wantToRun, isOfType := task.(goImports); isOfType {
-			if err := wantToRun.Exec(ctx); err != nil {
-				return fmt.Errorf("running Go formatter after code generation: %w", err)
-			}
+	// run those here again as well, against the same files as they ran against
+	for _, formatter := range t.formatters {
+		if err := formatter.Exec(ctx); err != nil {
+			return fmt.Errorf("running Go formatter after code generation: %w", err)
 		}
 	}
 
@@ -207,7 +234,7 @@ func (t staticcheck) InfoText() string { return "Lint (staticcheck)" }
 
 // Exec implements [taskutil.Tasker.Exec].
 func (t staticcheck) Exec(ctx context.Context) error {
-	if err := toolcfg.SetupConfigFile(t.Tool); err != nil {
+	if err := toolcfg.SetupConfigFile(ctx, t.Tool); err != nil {
 		return err
 	}
 
@@ -219,8 +246,8 @@ func (t staticcheck) Exec(ctx context.Context) error {
 }
 
 // Post implements [taskutil.Tasker.Post].
-func (t staticcheck) Post(_ context.Context) error {
-	if err := os.RemoveAll(t.ConfigFilePath); err != nil {
+func (t staticcheck) Post(ctx context.Context) error {
+	if err := os.RemoveAll(system.ResolvePath(ctx, t.ConfigFilePath)); err != nil {
 		return fmt.Errorf("removing config file: %w", err)
 	}
 
@@ -232,7 +259,7 @@ func (t revive) InfoText() string { return "Lint (revive)" }
 
 // Exec implements [taskutil.Tasker.Exec].
 func (t revive) Exec(ctx context.Context) error {
-	if err := toolcfg.SetupConfigFile(t.Tool); err != nil {
+	if err := toolcfg.SetupConfigFile(ctx, t.Tool); err != nil {
 		return err
 	}
 
@@ -244,13 +271,10 @@ func (t revive) Exec(ctx context.Context) error {
 }
 
 // Post implements [taskutil.Tasker.Post].
-func (t revive) Post(_ context.Context) error {
-	if err := os.RemoveAll(t.ConfigFilePath); err != nil {
-		return fmt.Errorf("removing config file: %w", err)
-	}
-
-	return nil
-}
+//
+// NOTE: the config file is left in place, since revive may still be running for another project
+// that shares the same file
+func (t revive) Post(_ context.Context) error { return nil }
 
 // InfoText implements [taskutil.Tasker.InfoText].
 func (t errcheck) InfoText() string { return "Lint (errcheck)" }
@@ -0,0 +1,29 @@
+package gotools
+
+import (
+	"testing"
+
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCodeCIFormatters(t *testing.T) {
+	repo := taskutil.Repo{
+		HasGo:        true,
+		Files:        []string{"go.mod", "a/a.go", "b/b.go"},
+		ChangedFiles: []string{"a/a.go"},
+	}
+
+	var generate generateCodeCI
+	for _, task := range NewTasksForCI(repo) {
+		if g, ok := task.(generateCodeCI); ok {
+			generate = g
+		}
+	}
+	require.Len(t, generate.formatters, 2)
+
+	// The formatters rerun after generating code must stay limited to the changed files
+	assert.Equal(t, []string{"go", "fmt", "./a"}, generate.formatters[0].ToolInfo().RunArgs)
+	assert.Equal(t, []string{"goimports", "-l", "-w", "a/a.go"}, generate.formatters[1].ToolInfo().RunArgs)
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/opensourcecorp/oscar/internal/changelog"
 	"github.com/opensourcecorp/oscar/internal/oscarcfg"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 	"github.com/opensourcecorp/oscar/internal/system"
@@ -43,7 +44,7 @@ func NewTasksForDelivery(repo taskutil.Repo) ([]taskutil.Tasker, error) {
 func (t ghRelease) InfoText() string { return "GitHub Release" }
 
 // Exec implements [taskutil.Tasker.Exec].
-func (t ghRelease) Exec(ctx context.Context) error {
+func (t ghRelease) Exec(ctx context.Context) (err error) {
 	cfg, err := oscarcfg.Get()
 	if err != nil {
 		return err
@@ -83,9 +84,25 @@ func (t ghRelease) Exec(ctx context.Context) error {
 		latestFlag = "--latest"
 	}
 
+	// Use the version's changelog section as the Release notes if there is a changelog, otherwise
+	// let GitHub generate them
+	notesFlag := "--generate-notes"
+	notesFile, err := writeReleaseNotes(ctx, cfg.GetVersion())
+	if err != nil {
+		return err
+	}
+	if notesFile != "" {
+		defer func() {
+			if rmErr := os.RemoveAll(notesFile); rmErr != nil {
+				err = errors.Join(err, fmt.Errorf("removing release notes file: %w", rmErr))
+			}
+		}()
+		notesFlag = fmt.Sprintf("--notes-file '%s'", notesFile)
+	}
+
 	args := []string{"bash", "-c", fmt.Sprintf(`
-		gh release create v%s %s --generate-notes --verify-tag %s ./dist/*
-		`, cfg.GetVersion(), draftFlag, latestFlag,
+		gh release create v%s %s %s --verify-tag %s ./dist/*
+		`, cfg.GetVersion(), draftFlag, notesFlag, latestFlag,
 	)}
 
 	if _, err := system.RunCommand(ctx, args); err != nil {
@@ -98,6 +115,36 @@ func (t ghRelease) Exec(ctx context.Context) error {
 // Post implements [taskutil.Tasker.Post].
 func (t ghRelease) Post(_ context.Context) error { return nil }
 
+// writeReleaseNotes writes the changelog section for the provided version to a temporary file, and
+// returns that file's path. If the repo has no changelog, it returns an empty path.
+func writeReleaseNotes(ctx context.Context, version string) (string, error) {
+	data, err := os.ReadFile(system.ResolvePath(ctx, changelog.FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", changelog.FileName, err)
+	}
+
+	notes, err := changelog.Section(data, version)
+	if err != nil {
+		return "", err
+	}
+
+	notesFile, err := os.CreateTemp("", "oscar-release-notes-*.md")
+	if err != nil {
+		return "", fmt.Errorf("creating release notes file: %w", err)
+	}
+	if _, err := notesFile.WriteString(notes + "\n"); err != nil {
+		return "", errors.Join(fmt.Errorf("writing release notes file: %w", err), notesFile.Close())
+	}
+	if err := notesFile.Close(); err != nil {
+		return "", fmt.Errorf("closing release notes file: %w", err)
+	}
+
+	return notesFile.Name(), nil
+}
+
 // goBuild cross-compiles the provided source package and places the resulting artifacts in a
 // root-level "build/" subdirectory.
 func goBuild(ctx context.Context, src string) error {
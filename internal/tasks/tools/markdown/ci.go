@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/opensourcecorp/oscar/internal/system"
 	"github.com/opensourcecorp/oscar/internal/tasks/tools/toolcfg"
@@ -20,7 +21,10 @@ func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
 		return []taskutil.Tasker{
 			markdownlint{
 				Tool: taskutil.Tool{
-					RunArgs:        []string{"markdownlint-cli2", "--config", "{{ConfigFilePath}}", "**/*.md"},
+					RunArgs: slices.Concat(
+						[]string{"markdownlint-cli2", "--config", "{{ConfigFilePath}}"},
+						repo.FilesOfType("md"),
+					),
 					ConfigFilePath: filepath.Join(os.TempDir(), ".markdownlint-cli2.yaml"),
 				},
 			},
@@ -35,7 +39,7 @@ func (t markdownlint) InfoText() string { return "Lint (markdownlint)" }
 
 // Exec implements [taskutil.Tasker.Exec].
 func (t markdownlint) Exec(ctx context.Context) error {
-	if err := toolcfg.SetupConfigFile(t.Tool); err != nil {
+	if err := toolcfg.SetupConfigFile(ctx, t.Tool); err != nil {
 		return err
 	}
 
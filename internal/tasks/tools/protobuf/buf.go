@@ -0,0 +1,299 @@
+package prototools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/opensourcecorp/oscar/internal/tasks/tools/toolcfg"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+	"go.yaml.in/yaml/v4"
+)
+
+const (
+	// bufConfigFileName is the name of the config file that marks the root of a buf module.
+	bufConfigFileName = "buf.yaml"
+	// bufGenTemplateFileName is the name of the template file that configures `buf generate`.
+	bufGenTemplateFileName = "buf.gen.yaml"
+)
+
+// findModuleDirs returns the root directory of every buf module that has Protobuf files in the
+// repo (or only changed Protobuf files, if the run is limited to those).
+func findModuleDirs(ctx context.Context, repo taskutil.Repo) []string {
+	out := make([]string, 0)
+	for _, file := range repo.FilesOfType("protobuf") {
+		dir := moduleDirForFile(ctx, file)
+		if !slices.Contains(out, dir) {
+			out = append(out, dir)
+		}
+	}
+	slices.Sort(out)
+
+	return out
+}
+
+// moduleDirForFile returns the root directory of the buf module that the provided Protobuf file
+// belongs to, i.e. its nearest parent directory with a buf config file. If there is none, the
+// current directory is used as the module root, same as buf itself does.
+func moduleDirForFile(ctx context.Context, file string) string {
+	for dir := filepath.Dir(file); dir != "." && dir != "/"; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(system.ResolvePath(ctx, filepath.Join(dir, bufConfigFileName))); err == nil {
+			return dir
+		}
+	}
+
+	return "."
+}
+
+// runWithModuleConfig runs the Tool against the buf module in dir, using that module's own config
+// file but with oscar's lint & breaking-change rules swapped in.
+func runWithModuleConfig(ctx context.Context, tool taskutil.Tool, dir string) (err error) {
+	moduleCfg, err := os.ReadFile(system.ResolvePath(ctx, filepath.Join(dir, bufConfigFileName)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading buf config for module '%s': %w", dir, err)
+	}
+
+	oscarCfg, err := toolcfg.Files.ReadFile(bufConfigFileName)
+	if err != nil {
+		return fmt.Errorf("reading embedded file contents: %w", err)
+	}
+
+	mergedCfg, err := mergeConfig(moduleCfg, oscarCfg)
+	if err != nil {
+		return fmt.Errorf("merging buf config for module '%s': %w", dir, err)
+	}
+
+	// NOTE: each module needs its own config file, and Tasks may run concurrently, so this can't
+	// use a fixed path
+	cfgFile, err := os.CreateTemp("", "oscar-buf-*.yaml")
+	if err != nil {
+		return fmt.Errorf("creating buf config file: %w", err)
+	}
+	defer func() {
+		if rmErr := os.RemoveAll(cfgFile.Name()); rmErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing buf config file: %w", rmErr))
+		}
+	}()
+	if _, err := cfgFile.Write(mergedCfg); err != nil {
+		return errors.Join(fmt.Errorf("writing buf config file: %w", err), cfgFile.Close())
+	}
+	if err := cfgFile.Close(); err != nil {
+		return fmt.Errorf("closing buf config file: %w", err)
+	}
+
+	tool.ConfigFilePath = cfgFile.Name()
+	if _, err := system.RunCommand(ctx, append(tool.RenderRunCommandArgs(), dir)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// mergeConfig returns the provided buf module config, with its lint & breaking-change sections
+// replaced by oscar's. If the module has no config, oscar's is used as-is.
+func mergeConfig(moduleCfg []byte, oscarCfg []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(moduleCfg)) == 0 {
+		return oscarCfg, nil
+	}
+
+	var module, oscar map[string]any
+	if err := yaml.Unmarshal(moduleCfg, &module); err != nil {
+		return nil, fmt.Errorf("unmarshalling module config: %w", err)
+	}
+	if err := yaml.Unmarshal(oscarCfg, &oscar); err != nil {
+		return nil, fmt.Errorf("unmarshalling oscar config: %w", err)
+	}
+
+	for _, section := range []string{"lint", "breaking"} {
+		module[section] = oscar[section]
+	}
+
+	out, err := yaml.Marshal(module)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling merged config: %w", err)
+	}
+
+	return out, nil
+}
+
+// checkGeneratedCode runs `buf generate` for the module in dir, but writes its output to a temporary
+// directory instead of where the module's template says to. It then returns an error if that output
+// differs at all from what is already in the repo. This avoids changing the repo's generated code
+// out from under any other Tasks that are running at the same time.
+func checkGeneratedCode(ctx context.Context, tool taskutil.Tool, dir string) (err error) {
+	tmpDir, err := os.MkdirTemp("", "oscar-buf-generate-")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer func() {
+		if rmErr := os.RemoveAll(tmpDir); rmErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing temporary directory: %w", rmErr))
+		}
+	}()
+
+	templateData, err := os.ReadFile(system.ResolvePath(ctx, filepath.Join(dir, bufGenTemplateFileName)))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", bufGenTemplateFileName, err)
+	}
+
+	template, outDirs, clean, err := redirectTemplate(templateData, system.ResolvePath(ctx, dir), tmpDir)
+	if err != nil {
+		return err
+	}
+
+	templatePath := filepath.Join(tmpDir, bufGenTemplateFileName)
+	if err := os.WriteFile(templatePath, template, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", bufGenTemplateFileName, err)
+	}
+
+	tool.ConfigFilePath = templatePath
+	if _, err := system.RunCommand(ctx, append(tool.RenderRunCommandArgs(), dir)); err != nil {
+		return err
+	}
+
+	drift := make([]string, 0)
+	for _, outDir := range outDirs {
+		diff, err := diffGeneratedDir(outDir.tmp, outDir.repo, clean)
+		if err != nil {
+			return err
+		}
+		drift = append(drift, diff...)
+	}
+
+	if len(drift) > 0 {
+		return fmt.Errorf(
+			"generated code is out of date, run 'buf generate' in '%s' to update it -- affected files: %v",
+			dir, drift,
+		)
+	}
+
+	return nil
+}
+
+// generatedOutDir pairs an output directory from a `buf generate` template with the temporary
+// directory that it was redirected to.
+type generatedOutDir struct {
+	repo string
+	tmp  string
+}
+
+// redirectTemplate rewrites the provided `buf generate` template so that each plugin writes to a
+// directory under tmpDir instead, and so that it never deletes anything. Relative output paths are
+// treated as relative to moduleDir. It returns the rewritten template, the mapping of output
+// directories, and whether the original template would have cleaned its output directories first.
+func redirectTemplate(
+	template []byte, moduleDir string, tmpDir string,
+) ([]byte, []generatedOutDir, bool, error) {
+	var cfg map[string]any
+	if err := yaml.Unmarshal(template, &cfg); err != nil {
+		return nil, nil, false, fmt.Errorf("unmarshalling %s: %w", bufGenTemplateFileName, err)
+	}
+
+	clean, _ := cfg["clean"].(bool)
+	cfg["clean"] = false
+
+	plugins, _ := cfg["plugins"].([]any)
+	outDirs := make([]generatedOutDir, 0)
+	for _, p := range plugins {
+		plugin, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		out, ok := plugin["out"].(string)
+		if !ok {
+			return nil, nil, false, fmt.Errorf("plugin in %s has no 'out' path", bufGenTemplateFileName)
+		}
+
+		repoOut := out
+		if !filepath.IsAbs(repoOut) {
+			repoOut = filepath.Join(moduleDir, repoOut)
+		}
+
+		// Plugins that share an output directory must also share a temporary one
+		index := slices.IndexFunc(outDirs, func(d generatedOutDir) bool { return d.repo == repoOut })
+		if index == -1 {
+			outDirs = append(outDirs, generatedOutDir{
+				repo: repoOut,
+				tmp:  filepath.Join(tmpDir, "out", strconv.Itoa(len(outDirs))),
+			})
+			index = len(outDirs) - 1
+		}
+		plugin["out"] = outDirs[index].tmp
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("marshalling %s: %w", bufGenTemplateFileName, err)
+	}
+
+	return out, outDirs, clean, nil
+}
+
+// diffGeneratedDir returns the paths of any files in repoDir that don't match the freshly-generated
+// files in generatedDir. If clean is true, files in repoDir that were not generated at all are also
+// included, since `buf generate` would have removed them.
+func diffGeneratedDir(generatedDir string, repoDir string, clean bool) ([]string, error) {
+	out := make([]string, 0)
+
+	generated := make(map[string]bool)
+	err := filepath.WalkDir(generatedDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(generatedDir, path)
+		if err != nil {
+			return err
+		}
+		generated[rel] = true
+
+		want, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		got, err := os.ReadFile(filepath.Join(repoDir, rel))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err != nil || !bytes.Equal(want, got) {
+			out = append(out, filepath.Join(repoDir, rel))
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("comparing generated files: %w", err)
+	}
+
+	if clean {
+		err := filepath.WalkDir(repoDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(repoDir, path)
+			if err != nil {
+				return err
+			}
+			if !generated[rel] {
+				out = append(out, path)
+			}
+
+			return nil
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("comparing generated files: %w", err)
+		}
+	}
+
+	slices.Sort(out)
+
+	return out, nil
+}
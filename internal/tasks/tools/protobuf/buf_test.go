@@ -0,0 +1,107 @@
+package prototools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestMergeConfig(t *testing.T) {
+	moduleCfg := []byte(`---
+version: "v2"
+deps:
+  - "buf.build/bufbuild/protovalidate"
+lint:
+  use:
+    - "MINIMAL"
+`)
+	oscarCfg := []byte(`---
+version: "v2"
+lint:
+  use:
+    - "STANDARD"
+breaking:
+  use:
+    - "FILE"
+`)
+
+	merged, err := mergeConfig(moduleCfg, oscarCfg)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, yaml.Unmarshal(merged, &got))
+
+	want := map[string]any{
+		"version":  "v2",
+		"deps":     []any{"buf.build/bufbuild/protovalidate"},
+		"lint":     map[string]any{"use": []any{"STANDARD"}},
+		"breaking": map[string]any{"use": []any{"FILE"}},
+	}
+	assert.Equal(t, want, got)
+
+	// A module with no config just gets oscar's
+	merged, err = mergeConfig(nil, oscarCfg)
+	require.NoError(t, err)
+	assert.Equal(t, oscarCfg, merged)
+}
+
+func TestRedirectTemplate(t *testing.T) {
+	template := []byte(`---
+version: "v2"
+clean: true
+plugins:
+  - local: "protoc-gen-go"
+    out: "../internal/generated"
+  - local: "protoc-gen-go-grpc"
+    out: "../internal/generated"
+`)
+
+	redirected, outDirs, clean, err := redirectTemplate(template, "proto", "/tmp/x")
+	require.NoError(t, err)
+
+	assert.True(t, clean)
+	assert.Equal(t, []generatedOutDir{{repo: "internal/generated", tmp: "/tmp/x/out/0"}}, outDirs)
+
+	var got map[string]any
+	require.NoError(t, yaml.Unmarshal(redirected, &got))
+	assert.Equal(t, false, got["clean"])
+	for _, plugin := range got["plugins"].([]any) {
+		assert.Equal(t, "/tmp/x/out/0", plugin.(map[string]any)["out"])
+	}
+}
+
+func TestDiffGeneratedDir(t *testing.T) {
+	generatedDir := t.TempDir()
+	repoDir := t.TempDir()
+
+	writeFile := func(path string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+
+	writeFile(filepath.Join(generatedDir, "a", "same.pb.go"), "same")
+	writeFile(filepath.Join(repoDir, "a", "same.pb.go"), "same")
+	writeFile(filepath.Join(generatedDir, "a", "changed.pb.go"), "new")
+	writeFile(filepath.Join(repoDir, "a", "changed.pb.go"), "old")
+	writeFile(filepath.Join(generatedDir, "b", "missing.pb.go"), "new")
+	writeFile(filepath.Join(repoDir, "b", "stale.pb.go"), "old")
+
+	got, err := diffGeneratedDir(generatedDir, repoDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(repoDir, "a", "changed.pb.go"),
+		filepath.Join(repoDir, "b", "missing.pb.go"),
+	}, got)
+
+	got, err = diffGeneratedDir(generatedDir, repoDir, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(repoDir, "a", "changed.pb.go"),
+		filepath.Join(repoDir, "b", "missing.pb.go"),
+		filepath.Join(repoDir, "b", "stale.pb.go"),
+	}, got)
+}
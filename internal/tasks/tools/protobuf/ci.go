@@ -0,0 +1,218 @@
+package prototools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/opensourcecorp/oscar/internal/consts"
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	"github.com/opensourcecorp/oscar/internal/oscarcfg"
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"github.com/opensourcecorp/oscar/internal/system"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+)
+
+// NOTE: unlike most Tasks, these also need to know about the repo, because they run once per buf
+// module rather than once against a list of files
+type (
+	bufFormat struct {
+		taskutil.Tool
+		repo taskutil.Repo
+	}
+	bufLint struct {
+		taskutil.Tool
+		repo taskutil.Repo
+	}
+	bufGenerate struct {
+		taskutil.Tool
+		repo taskutil.Repo
+	}
+	bufBreaking struct {
+		taskutil.Tool
+		repo taskutil.Repo
+	}
+)
+
+// NewTasksForCI returns the list of CI tasks.
+func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
+	if repo.HasProtobuf {
+		return []taskutil.Tasker{
+			bufFormat{
+				Tool: taskutil.Tool{
+					RunArgs:  []string{"buf", "format", "--write"},
+					Mutating: true,
+				},
+				repo: repo,
+			},
+			bufLint{
+				Tool: taskutil.Tool{
+					// NOTE: ConfigFilePath is set separately for each buf module, at runtime
+					RunArgs:   []string{"buf", "lint", "--config", "{{ConfigFilePath}}"},
+					DependsOn: []string{bufFormat{}.InfoText()},
+				},
+				repo: repo,
+			},
+			bufGenerate{
+				Tool: taskutil.Tool{
+					// NOTE: ConfigFilePath is set separately for each buf module, at runtime
+					RunArgs:   []string{"buf", "generate", "--template", "{{ConfigFilePath}}"},
+					DependsOn: []string{bufFormat{}.InfoText()},
+				},
+				repo: repo,
+			},
+			bufBreaking{
+				Tool: taskutil.Tool{
+					// NOTE: ConfigFilePath is set separately for each buf module, at runtime
+					RunArgs:   []string{"buf", "breaking", "--config", "{{ConfigFilePath}}"},
+					DependsOn: []string{bufFormat{}.InfoText()},
+					// NOTE: this compares against the base branch, which can change without this
+					// repo's files changing at all
+					Uncacheable: true,
+				},
+				repo: repo,
+			},
+		}
+	}
+
+	return nil
+}
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t bufFormat) InfoText() string { return "Format (buf)" }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t bufFormat) Exec(ctx context.Context) error {
+	var errs error
+	for _, dir := range findModuleDirs(ctx, t.repo) {
+		// NOTE: any formatting changes are caught by the Git diff check after the Task finishes
+		if _, err := system.RunCommand(ctx, slices.Concat(t.RunArgs, []string{dir})); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t bufFormat) Post(_ context.Context) error { return nil }
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t bufLint) InfoText() string { return "Lint (buf)" }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t bufLint) Exec(ctx context.Context) error {
+	var errs error
+	for _, dir := range findModuleDirs(ctx, t.repo) {
+		if err := runWithModuleConfig(ctx, t.Tool, dir); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t bufLint) Post(_ context.Context) error { return nil }
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t bufGenerate) InfoText() string { return "Generated code check (buf)" }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t bufGenerate) Exec(ctx context.Context) error {
+	var errs error
+	for _, dir := range findModuleDirs(ctx, t.repo) {
+		templatePath := system.ResolvePath(ctx, filepath.Join(dir, bufGenTemplateFileName))
+		if _, err := os.Stat(templatePath); errors.Is(err, os.ErrNotExist) {
+			iprint.Debugf("no %s in '%s', so not checking generated code\n", bufGenTemplateFileName, dir)
+			continue
+		}
+
+		if err := checkGeneratedCode(ctx, t.Tool, dir); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("checking generated code for module '%s': %w", dir, err))
+		}
+	}
+
+	return errs
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t bufGenerate) Post(_ context.Context) error { return nil }
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t bufBreaking) InfoText() string { return "Breaking change check (buf)" }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t bufBreaking) Exec(ctx context.Context) error {
+	baseBranch, err := configuredBaseBranch()
+	if err != nil {
+		return err
+	}
+
+	// CI tasks shouldn't need the network just to find the base branch, so only look for it locally
+	baseRef, ok := igit.LocalBaseBranchRef(ctx, baseBranch)
+	if !ok {
+		iprint.Warnf(
+			"base branch '%s' not found locally or on origin, so not checking for breaking changes (run `git fetch origin %s` to check against it)\n",
+			baseBranch, baseBranch,
+		)
+		return nil
+	}
+
+	// The base branch's paths are relative to the repo root, which isn't necessarily the root of the
+	// project being run against
+	repoRoot, err := system.RunCommand(ctx, []string{"git", "rev-parse", "--show-cdup"})
+	if err != nil {
+		return fmt.Errorf("finding repo root: %w", err)
+	}
+	projectPrefix, err := system.RunCommand(ctx, []string{"git", "rev-parse", "--show-prefix"})
+	if err != nil {
+		return fmt.Errorf("finding project path in repo: %w", err)
+	}
+
+	var errs error
+	for _, dir := range findModuleDirs(ctx, t.repo) {
+		against := repoRoot + ".git#ref=" + baseRef
+		baseDir := baseRef + ":"
+		if subdir := path.Join(projectPrefix, filepath.ToSlash(dir)); subdir != "." {
+			against += ",subdir=" + subdir
+			baseDir += subdir
+		}
+
+		// A module that doesn't exist on the base branch yet can't have broken anything
+		if _, err := system.RunCommand(ctx, []string{"git", "cat-file", "-e", baseDir}); err != nil {
+			iprint.Debugf("'%s' not found, so not checking it for breaking changes\n", baseDir)
+			continue
+		}
+
+		tool := t.Tool
+		tool.RunArgs = slices.Concat(tool.RunArgs, []string{"--against", against})
+		if err := runWithModuleConfig(ctx, tool, dir); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t bufBreaking) Post(_ context.Context) error { return nil }
+
+// configuredBaseBranch returns the base branch from the oscar config file. The config file is
+// optional for this, so if there isn't one, the default base branch is used.
+func configuredBaseBranch() (string, error) {
+	if _, err := os.Stat(consts.DefaultOscarCfgFileName); errors.Is(err, os.ErrNotExist) {
+		return oscarcfg.DefaultBaseBranch, nil
+	}
+
+	cfg, err := oscarcfg.Get()
+	if err != nil {
+		return "", fmt.Errorf("getting oscar config: %w", err)
+	}
+
+	return oscarcfg.BaseBranch(cfg), nil
+}
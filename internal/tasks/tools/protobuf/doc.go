@@ -0,0 +1,2 @@
+// Package prototools contains logic for running tasks for Protobuf, via buf.
+package prototools
@@ -2,6 +2,7 @@ package pytools
 
 import (
 	"context"
+	"slices"
 
 	"github.com/opensourcecorp/oscar/internal/system"
 	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
@@ -18,30 +19,44 @@ type (
 // NewTasksForCI returns the list of CI tasks.
 func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
 	if repo.HasPython {
+		ruffTargets := []string{"./src"}
+		if repo.ChangedOnly() {
+			ruffTargets = repo.FilesOfType("py")
+		}
+
 		return []taskutil.Tasker{
 			buildTask{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"uv", "build"},
+					RunArgs:   []string{"uv", "build"},
+					DependsOn: []string{ruffFormat{}.InfoText()},
 				},
 			},
 			ruffLint{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"ruff", "check", "--fix", "./src"},
+					RunArgs: slices.Concat(
+						[]string{"ruff", "check", "--fix", "--output-format", "concise"},
+						ruffTargets,
+					),
+					Mutating: true,
 				},
 			},
 			ruffFormat{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"ruff", "format", "./src"},
+					RunArgs:   slices.Concat([]string{"ruff", "format"}, ruffTargets),
+					DependsOn: []string{ruffLint{}.InfoText()},
+					Mutating:  true,
 				},
 			},
 			pydoclint{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"uvx", "pydoclint", "./src"},
+					RunArgs:   []string{"uvx", "pydoclint", "./src"},
+					DependsOn: []string{ruffFormat{}.InfoText()},
 				},
 			},
 			mypy{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"uvx", "mypy", "./src"},
+					RunArgs:   []string{"uvx", "mypy", "./src"},
+					DependsOn: []string{ruffFormat{}.InfoText()},
 				},
 			},
 		}
@@ -2,6 +2,7 @@ package shtools
 
 import (
 	"context"
+	"slices"
 
 	"github.com/opensourcecorp/oscar/internal/system"
 	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
@@ -18,20 +19,14 @@ func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
 		return []taskutil.Tasker{
 			shellcheck{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"bash", "-c", `
-						shopt -s globstar
-						ls **/*.sh || exit 0
-						shellcheck **/*.sh`,
-					},
+					RunArgs:   slices.Concat([]string{"shellcheck", "--format", "gcc"}, repo.FilesOfType("sh")),
+					DependsOn: []string{shfmt{}.InfoText()},
 				},
 			},
 			shfmt{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"bash", "-c", `
-						shopt -s globstar
-						ls **/*.sh || exit 0
-						shfmt **/*.sh`,
-					},
+					RunArgs:  slices.Concat([]string{"shfmt", "-w"}, repo.FilesOfType("sh")),
+					Mutating: true,
 				},
 			},
 		}
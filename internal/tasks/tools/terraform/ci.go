@@ -0,0 +1,247 @@
+package tftools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/opensourcecorp/oscar/internal/tasks/tools/toolcfg"
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+)
+
+type (
+	tfFormat struct{ taskutil.Tool }
+	// NOTE: unlike most Tasks, this one also needs to know about the repo, because it runs once per
+	// root module rather than once against a list of files
+	tfValidate struct {
+		taskutil.Tool
+		repo taskutil.Repo
+	}
+	tflint struct{ taskutil.Tool }
+)
+
+// NewTasksForCI returns the list of CI tasks.
+func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
+	if repo.HasTerraform {
+		// NOTE: this rewrites files instead of using `-check`, so that `oscar fix` can use it too --
+		// any changes are caught by the Git diff check after the Task finishes
+		fmtArgs := []string{"terraform", "fmt", "-recursive"}
+		if repo.ChangedOnly() {
+			fmtArgs = slices.Concat([]string{"terraform", "fmt"}, repo.FilesOfType("tf"))
+		}
+
+		return []taskutil.Tasker{
+			tfFormat{
+				Tool: taskutil.Tool{
+					RunArgs:  fmtArgs,
+					Mutating: true,
+				},
+			},
+			tfValidate{
+				Tool: taskutil.Tool{
+					RunArgs:   []string{"terraform", "validate", "-no-color"},
+					TempPaths: generatedLockFiles(repo),
+					DependsOn: []string{tfFormat{}.InfoText()},
+				},
+				repo: repo,
+			},
+			tflint{
+				Tool: taskutil.Tool{
+					RunArgs: []string{
+						"tflint", "--config", "{{ConfigFilePath}}", "--recursive", "--format", "compact",
+					},
+					// NOTE: must be an absolute path, otherwise tflint looks for it relative to each
+					// module during recursive runs
+					ConfigFilePath: filepath.Join(os.TempDir(), ".tflint.hcl"),
+					DependsOn:      []string{tfFormat{}.InfoText()},
+				},
+			},
+		}
+	}
+
+	return nil
+}
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t tfFormat) InfoText() string { return "Format" }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t tfFormat) Exec(ctx context.Context) error {
+	if _, err := system.RunCommand(ctx, t.RunArgs); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t tfFormat) Post(_ context.Context) error { return nil }
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t tfValidate) InfoText() string { return "Validate" }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t tfValidate) Exec(ctx context.Context) error {
+	var errs error
+	for _, dir := range rootModuleDirs(t.repo.FilesOfType("tf")) {
+		if err := t.validateModule(ctx, dir); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("validating root module '%s': %w", dir, err))
+		}
+	}
+
+	return errs
+}
+
+// validateModule initializes & validates a single root module. Terraform's working data (normally
+// written to a `.terraform` directory in the module) is kept in a temporary directory instead. An
+// existing lock file is only read, never updated, and any lock file that didn't already exist is
+// removed afterwards (see [generatedLockFiles]), so that validating never leaves anything behind in
+// the repo.
+func (t tfValidate) validateModule(ctx context.Context, dir string) (err error) {
+	dataDir, err := os.MkdirTemp("", "oscar-terraform-")
+	if err != nil {
+		return fmt.Errorf("creating Terraform data directory: %w", err)
+	}
+	defer func() {
+		if rmErr := os.RemoveAll(dataDir); rmErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing Terraform data directory: %w", rmErr))
+		}
+	}()
+
+	// NOTE: terraform has no flag for its data directory, so set it via env var instead
+	ctx = system.WithEnv(ctx, "TF_DATA_DIR="+dataDir)
+	baseArgs := []string{"terraform", "-chdir=" + dir}
+
+	initArgs := slices.Concat(baseArgs, []string{
+		"init", "-backend=false", "-input=false", "-no-color",
+	})
+
+	lockFilePath := system.ResolvePath(ctx, filepath.Join(dir, lockFileName))
+	if _, statErr := os.Stat(lockFilePath); errors.Is(statErr, os.ErrNotExist) {
+		defer func() {
+			if rmErr := os.RemoveAll(lockFilePath); rmErr != nil {
+				err = errors.Join(err, fmt.Errorf("removing generated lock file: %w", rmErr))
+			}
+		}()
+	} else {
+		// Otherwise, init would add checksums for the host's platform to it
+		initArgs = append(initArgs, "-lockfile=readonly")
+	}
+	if _, err := system.RunCommand(ctx, initArgs); err != nil {
+		// When offline, the above fails trying to reach the provider registry -- so if there are any
+		// locally-cached providers, try again using only those
+		pluginDirs := localPluginDirs()
+		if len(pluginDirs) == 0 {
+			return err
+		}
+
+		offlineArgs := slices.Clone(initArgs)
+		for _, pluginDir := range pluginDirs {
+			offlineArgs = append(offlineArgs, "-plugin-dir="+pluginDir)
+		}
+		if _, offlineErr := system.RunCommand(ctx, offlineArgs); offlineErr != nil {
+			return errors.Join(err, offlineErr)
+		}
+	}
+
+	// NOTE: baseArgs already includes the leading "terraform" from RunArgs
+	if _, err := system.RunCommand(ctx, slices.Concat(baseArgs, t.RunArgs[1:])); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t tfValidate) Post(_ context.Context) error { return nil }
+
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t tflint) InfoText() string { return "Lint (tflint)" }
+
+// Exec implements [taskutil.Tasker.Exec].
+func (t tflint) Exec(ctx context.Context) error {
+	if err := toolcfg.SetupConfigFile(ctx, t.Tool); err != nil {
+		return err
+	}
+
+	if _, err := system.RunCommand(ctx, t.RenderRunCommandArgs()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Post implements [taskutil.Tasker.Post].
+func (t tflint) Post(_ context.Context) error { return nil }
+
+// lockFileName is the name of the dependency lock file that `terraform init` writes to a module.
+const lockFileName = ".terraform.lock.hcl"
+
+// generatedLockFiles returns the paths of the lock files that validating the repo's root modules
+// will create & then remove, i.e. those of root modules that don't have one yet.
+func generatedLockFiles(repo taskutil.Repo) []string {
+	out := make([]string, 0)
+	for _, dir := range rootModuleDirs(repo.FilesOfType("tf")) {
+		lockFile := filepath.Join(dir, lockFileName)
+		if !slices.Contains(repo.Files, lockFile) {
+			out = append(out, lockFile)
+		}
+	}
+
+	return out
+}
+
+// rootModuleDirs returns the directories of every root module that the provided Terraform files
+// belong to. Directories under a "modules" directory are assumed to be child modules, which are
+// validated via the root modules that call them.
+func rootModuleDirs(files []string) []string {
+	out := make([]string, 0)
+	for _, file := range files {
+		if file == "" || filepath.Ext(file) != ".tf" {
+			continue
+		}
+
+		dir := filepath.Dir(file)
+		parts := strings.Split(filepath.ToSlash(dir), "/")
+		if slices.Contains(parts, "modules") || slices.Contains(parts, ".terraform") {
+			continue
+		}
+
+		if !slices.Contains(out, dir) {
+			out = append(out, dir)
+		}
+	}
+	slices.Sort(out)
+
+	return out
+}
+
+// localPluginDirs returns any directories on the host that may hold already-downloaded Terraform
+// providers, for use with `terraform init -plugin-dir`.
+func localPluginDirs() []string {
+	candidates := make([]string, 0)
+	if cacheDir := os.Getenv("TF_PLUGIN_CACHE_DIR"); cacheDir != "" {
+		candidates = append(candidates, cacheDir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(
+			candidates,
+			filepath.Join(home, ".terraform.d", "plugin-cache"),
+			filepath.Join(home, ".terraform.d", "plugins"),
+		)
+	}
+
+	out := make([]string, 0)
+	for _, dir := range candidates {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() && !slices.Contains(out, dir) {
+			out = append(out, dir)
+		}
+	}
+
+	return out
+}
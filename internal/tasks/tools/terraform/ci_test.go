@@ -0,0 +1,38 @@
+package tftools
+
+import (
+	"testing"
+
+	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootModuleDirs(t *testing.T) {
+	files := []string{
+		"main.tf",
+		"variables.tf",
+		"envs/prod/main.tf",
+		"envs/prod/terraform.tfvars",
+		"envs/dev/main.tf",
+		"modules/network/main.tf",
+		"envs/dev/.terraform/modules/network/main.tf",
+		"",
+	}
+
+	want := []string{".", "envs/dev", "envs/prod"}
+	got := rootModuleDirs(files)
+
+	assert.Equal(t, want, got)
+}
+
+func TestGeneratedLockFiles(t *testing.T) {
+	repo := taskutil.Repo{Files: []string{
+		"infra/prod/main.tf",
+		"infra/prod/.terraform.lock.hcl",
+		"infra/dev/main.tf",
+		"modules/network/main.tf",
+	}}
+
+	assert.Equal(t, []string{"infra/dev/.terraform.lock.hcl"}, generatedLockFiles(repo))
+}
@@ -0,0 +1,2 @@
+// Package tftools contains logic for running tasks for Terraform.
+package tftools
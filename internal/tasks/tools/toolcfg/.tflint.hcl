@@ -0,0 +1,24 @@
+# Rules found at: https://github.com/terraform-linters/tflint-ruleset-terraform/blob/main/docs/rules/README.md
+config {
+  call_module_type = "local"
+  force            = false
+}
+
+# NOTE: the terraform ruleset is bundled with tflint itself, so this works without downloading any
+# plugins
+plugin "terraform" {
+  enabled = true
+  preset  = "recommended"
+}
+
+rule "terraform_naming_convention" {
+  enabled = true
+}
+
+rule "terraform_documented_variables" {
+  enabled = true
+}
+
+rule "terraform_documented_outputs" {
+  enabled = true
+}
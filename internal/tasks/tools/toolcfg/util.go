@@ -1,22 +1,62 @@
 package toolcfg
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/opensourcecorp/oscar/internal/system"
 	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
 )
 
 // SetupConfigFile handles reading a Tool's config file from the embedded filesystem, and writing it
-// to its target location.
-func SetupConfigFile(t taskutil.Tool) error {
+// to its target location. Relative target locations are resolved against the Task's working
+// directory (see [system.ResolvePath]).
+func SetupConfigFile(ctx context.Context, t taskutil.Tool) (err error) {
 	cfgFileContents, err := Files.ReadFile(filepath.Base(t.ConfigFilePath))
 	if err != nil {
 		return fmt.Errorf("reading embedded file contents: %w", err)
 	}
 
-	if err := os.WriteFile(t.ConfigFilePath, cfgFileContents, 0644); err != nil {
+	cfgFilePath := system.ResolvePath(ctx, t.ConfigFilePath)
+
+	// A relative path is inside the project being run against, so only this Task uses it. Writing
+	// that one via a temporary file would only make the temporary file show up as a repo change.
+	if !filepath.IsAbs(t.ConfigFilePath) {
+		if err := os.WriteFile(cfgFilePath, cfgFileContents, 0644); err != nil {
+			return fmt.Errorf("writing config file: %w", err)
+		}
+		return nil
+	}
+
+	// Otherwise, the same Tool may be running for several projects at once, all sharing this file.
+	// So it's written atomically, lest one Task read it while another is partway through rewriting
+	// it.
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(cfgFilePath), "."+filepath.Base(cfgFilePath)+".*")
+	if err != nil {
+		return fmt.Errorf("creating config file: %w", err)
+	}
+	defer func() {
+		// NOTE: this is a no-op once the file has been renamed into place
+		if rmErr := os.RemoveAll(tmpFile.Name()); rmErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing temporary config file: %w", rmErr))
+		}
+	}()
+
+	if _, err := tmpFile.Write(cfgFileContents); err != nil {
+		return errors.Join(fmt.Errorf("writing config file: %w", err), tmpFile.Close())
+	}
+	if err := tmpFile.Chmod(0644); err != nil {
+		return errors.Join(fmt.Errorf("setting config file permissions: %w", err), tmpFile.Close())
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("closing config file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), cfgFilePath); err != nil {
 		return fmt.Errorf("writing config file: %w", err)
 	}
 
@@ -2,33 +2,47 @@ package versiontools
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
-	"regexp"
+	"slices"
 
-	"github.com/opensourcecorp/oscar/internal/consts"
+	"github.com/opensourcecorp/oscar/internal/changelog"
+	igit "github.com/opensourcecorp/oscar/internal/git"
 	"github.com/opensourcecorp/oscar/internal/oscarcfg"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 	"github.com/opensourcecorp/oscar/internal/system"
 	taskutil "github.com/opensourcecorp/oscar/internal/tasks/util"
 )
 
-type versionCI struct{ taskutil.Tool }
+type (
+	versionCI   struct{ taskutil.Tool }
+	changelogCI struct{ taskutil.Tool }
+)
 
 // NewTasksForCI returns the list of CI tasks.
-func NewTasksForCI(_ taskutil.Repo) []taskutil.Tasker {
-	return []taskutil.Tasker{
-		versionCI{},
+func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
+	out := []taskutil.Tasker{
+		versionCI{
+			Tool: taskutil.Tool{
+				// NOTE: this compares against the base branch, which can change without this repo's
+				// files changing at all
+				Uncacheable: true,
+			},
+		},
+	}
+
+	if slices.Contains(repo.Files, changelog.FileName) {
+		out = append(out, changelogCI{})
 	}
+
+	return out
 }
 
 // InfoText implements [taskutil.Tasker.InfoText].
 func (t versionCI) InfoText() string { return "Versioning checks" }
 
 // Exec implements [taskutil.Tasker.Exec].
-func (t versionCI) Exec(ctx context.Context) (err error) {
+func (t versionCI) Exec(ctx context.Context) error {
 	cfg, err := oscarcfg.Get()
 	if err != nil {
 		return fmt.Errorf("getting oscar config: %w", err)
@@ -36,56 +50,39 @@ func (t versionCI) Exec(ctx context.Context) (err error) {
 	version := cfg.GetVersion()
 	iprint.Debugf("provided version: %s\n", version)
 
-	// NOTE: we clone the repo in question to a temp location to check the version on
-	// the main branch, instead of e.g. trying to checkout main. This is for may reasons, not the
-	// least of which being that alternatives would be unreliable in e.g. GitHub Actions CI based on
-	// how it treats PR checkouts et al. A small price to pay for reliability.
-	tmpCloneDir := filepath.Join(os.TempDir(), "oscar-ci", "this-repo")
-	if err := os.MkdirAll(filepath.Dir(tmpCloneDir), 0755); err != nil {
-		return fmt.Errorf("creating temp clone parent directory: %w", err)
-	}
-	defer func() {
-		if rmErr := os.RemoveAll(tmpCloneDir); rmErr != nil {
-			err = errors.Join(err, fmt.Errorf("removing temp clone directory: %w", rmErr))
-		}
-	}()
-
-	remote, err := system.RunCommand(ctx, []string{"git", "remote", "get-url", "origin"})
+	// Need to check if we're already on the base branch, since checking its version against itself
+	// will unintentionally fail
+	baseBranch := oscarcfg.BaseBranch(cfg)
+	branch, err := system.RunCommand(ctx, []string{"git", "rev-parse", "--abbrev-ref", "HEAD"})
 	if err != nil {
-		return fmt.Errorf("determining git root: %w", err)
+		return fmt.Errorf("checking current Git branch/ref: %w", err)
 	}
-
-	remote = canonicalizeGitRemote(remote)
-
-	if _, err := system.RunCommand(ctx, []string{"git", "clone", "--depth", "1", remote, tmpCloneDir}); err != nil {
-		return fmt.Errorf("cloning repo source to temp location: %w", err)
+	iprint.Debugf("current Git branch/ref: %s\n", branch)
+	if branch == baseBranch {
+		return nil
 	}
 
-	mainCfg, err := oscarcfg.Get(filepath.Join(tmpCloneDir, consts.DefaultOscarCfgFileName))
+	// NOTE: this reads the base branch's config straight out of Git, instead of e.g. cloning the
+	// remote or checking out the branch. That way it works offline, with any kind of remote auth, and
+	// in CI systems like GitHub Actions that only check out the ref being built -- the base branch is
+	// only fetched if it isn't available locally already.
+	baseRef, err := igit.BaseBranchRef(ctx, baseBranch)
 	if err != nil {
-		return fmt.Errorf("getting oscar config: %w", err)
+		return err
 	}
-	mainVersion := mainCfg.GetVersion()
-	iprint.Debugf("main version: %s\n", version)
 
-	// Need to check if we're already on the main branch, since checking its version against itself
-	// will unintentionally fail
-	//
-	// TODO: update internal git package to have a type with ALL this info so I stop copy-pasting
-	// shell-outs around
-	branch, err := system.RunCommand(ctx, []string{"git", "rev-parse", "--abbrev-ref", "HEAD"})
+	baseCfg, err := oscarcfg.GetAtRef(ctx, baseRef)
 	if err != nil {
-		return fmt.Errorf("checking current Git branch/ref: %w", err)
+		return fmt.Errorf("getting oscar config from '%s': %w", baseRef, err)
 	}
-	iprint.Debugf("current Git branch/ref: %s\n", branch)
-
-	if branch != "main" {
-		if !oscarcfg.VersionHasBeenIncremented(version, mainVersion) {
-			return fmt.Errorf(
-				"version in oscar config on this branch (%s) has not been incremented from the version on the main branch",
-				version,
-			)
-		}
+	baseVersion := baseCfg.GetVersion()
+	iprint.Debugf("%s version: %s\n", baseRef, baseVersion)
+
+	if !oscarcfg.VersionHasBeenIncremented(version, baseVersion) {
+		return fmt.Errorf(
+			"version in oscar config on this branch (%s) has not been incremented from the version on '%s' (%s)",
+			version, baseRef, baseVersion,
+		)
 	}
 
 	return nil
@@ -94,12 +91,27 @@ func (t versionCI) Exec(ctx context.Context) (err error) {
 // Post implements [taskutil.Tasker.Post].
 func (t versionCI) Post(_ context.Context) error { return nil }
 
-// canonicalizeGitRemote converts a Git remote string to be in canonical HTTPS format.
-func canonicalizeGitRemote(remote string) string {
-	gitSSHRemoteRegex := regexp.MustCompile(`^(https://|git@)(.*)(:|/)(.*)/(.*(.git)?)$`)
-	groups := gitSSHRemoteRegex.FindStringSubmatch(remote)
+// InfoText implements [taskutil.Tasker.InfoText].
+func (t changelogCI) InfoText() string { return "Changelog checks" }
 
-	out := fmt.Sprintf("https://%s/%s/%s", groups[2], groups[4], groups[5])
+// Exec implements [taskutil.Tasker.Exec].
+func (t changelogCI) Exec(ctx context.Context) error {
+	cfg, err := oscarcfg.Get()
+	if err != nil {
+		return fmt.Errorf("getting oscar config: %w", err)
+	}
 
-	return out
+	data, err := os.ReadFile(system.ResolvePath(ctx, changelog.FileName))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", changelog.FileName, err)
+	}
+
+	if _, err := changelog.Section(data, cfg.GetVersion()); err != nil {
+		return err
+	}
+
+	return nil
 }
+
+// Post implements [taskutil.Tasker.Post].
+func (t changelogCI) Post(_ context.Context) error { return nil }
@@ -2,9 +2,9 @@ package yamltools
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/opensourcecorp/oscar/internal/system"
 	"github.com/opensourcecorp/oscar/internal/tasks/tools/toolcfg"
@@ -22,24 +22,22 @@ func NewTasksForCI(repo taskutil.Repo) []taskutil.Tasker {
 		return []taskutil.Tasker{
 			yamlfmt{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"bash", "-c",
-						fmt.Sprintf(
-							`yamlfmt -conf {{ConfigFilePath}} $(%s)`,
-							system.GetFileTypeListerCommand("yaml"),
-						),
-					},
+					RunArgs: slices.Concat(
+						[]string{"yamlfmt", "-conf", "{{ConfigFilePath}}"},
+						repo.FilesOfType("yaml"),
+					),
 					ConfigFilePath: filepath.Join(os.TempDir(), ".yamlfmt"),
+					Mutating:       true,
 				},
 			},
 			yamllint{
 				Tool: taskutil.Tool{
-					RunArgs: []string{"bash", "-c",
-						fmt.Sprintf(
-							`yamllint --strict --config-file {{ConfigFilePath}} $(%s)`,
-							system.GetFileTypeListerCommand("yaml"),
-						),
-					},
+					RunArgs: slices.Concat(
+						[]string{"yamllint", "--strict", "--format", "parsable", "--config-file", "{{ConfigFilePath}}"},
+						repo.FilesOfType("yaml"),
+					),
 					ConfigFilePath: filepath.Join(os.TempDir(), ".yamllint"),
+					DependsOn:      []string{yamlfmt{}.InfoText()},
 				},
 			},
 		}
@@ -53,7 +51,7 @@ func (t yamllint) InfoText() string { return "Lint (yamllint)" }
 
 // Run implements [taskutil.Tasker.Run].
 func (t yamllint) Exec(ctx context.Context) error {
-	if err := toolcfg.SetupConfigFile(t.Tool); err != nil {
+	if err := toolcfg.SetupConfigFile(ctx, t.Tool); err != nil {
 		return err
 	}
 
@@ -72,7 +70,7 @@ func (t yamlfmt) InfoText() string { return "Format (yamlfmt)" }
 
 // Run implements [taskutil.Tasker.Run].
 func (t yamlfmt) Exec(ctx context.Context) error {
-	if err := toolcfg.SetupConfigFile(t.Tool); err != nil {
+	if err := toolcfg.SetupConfigFile(ctx, t.Tool); err != nil {
 		return err
 	}
 
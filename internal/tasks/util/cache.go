@@ -0,0 +1,79 @@
+package taskutil
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opensourcecorp/oscar/internal/cache"
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+)
+
+// projectInputHashes returns a hash of the files in each of the provided projects (see
+// [cache.HashFiles]), keyed by [Project.Root]. Projects that are not part of the [Run], or whose
+// files could not be hashed, are left out, so that their Tasks are never cached.
+func (run Run) projectInputHashes(roots []string) map[string]string {
+	out := make(map[string]string)
+	for _, p := range run.Projects {
+		if !slices.Contains(roots, p.Root) {
+			continue
+		}
+
+		hash, err := cache.HashFiles(p.Root, p.Repo.Files)
+		if err != nil {
+			iprint.Warnf("not caching results for project '%s': %v\n", p.Root, err)
+			continue
+		}
+		out[p.Root] = hash
+	}
+
+	return out
+}
+
+// cacheKeys returns the [Run.Cache] key for every node, built from the Task's identity, its
+// rendered command, and the provided hash of its project's files. Nodes that must not be cached
+// have an empty key.
+func (run Run) cacheKeys(nodes []*node, inputHashes map[string]string) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		inputHash, found := inputHashes[n.project]
+		if !found || n.task.ToolInfo().Uncacheable {
+			continue
+		}
+
+		out[i] = run.Cache.Key(
+			TaskID(n.project, n.lang, n.task),
+			strings.Join(n.task.ToolInfo().RenderRunCommandArgs(), "\x00"),
+			inputHash,
+		)
+	}
+
+	return out
+}
+
+// storeCacheEntries caches every Task that passed (without itself being a cache hit). Since Tasks
+// may change files, entries are only stored for projects whose files are exactly the same as they
+// were before the run started -- otherwise the keys wouldn't describe what the Tasks actually ran
+// against.
+func (run Run) storeCacheEntries(results []Result, keys []string, inputHashes map[string]string) {
+	roots := make([]string, 0)
+	for root := range inputHashes {
+		roots = append(roots, root)
+	}
+	finalHashes := run.projectInputHashes(roots)
+
+	for i, result := range results {
+		if keys[i] == "" || result.Status != StatusPassed || result.Cached {
+			continue
+		}
+		if finalHashes[result.Project] != inputHashes[result.Project] {
+			iprint.Debugf("files changed during run, so not caching '%s'\n", result.ID())
+			continue
+		}
+
+		entry := cache.Entry{TaskID: result.ID(), PassedAt: time.Now()}
+		if err := run.Cache.Store(keys[i], entry); err != nil {
+			iprint.Warnf("caching result for '%s': %v\n", result.ID(), err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"slices"
 	"strings"
+	"time"
 
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 )
@@ -17,6 +18,9 @@ type Tasker interface {
 	Exec(ctx context.Context) error
 	// Post should perform any post-run actions for the task, if necessary.
 	Post(ctx context.Context) error
+	// ToolInfo should return the [Tool] that the task is built around. This is implemented for free
+	// by embedding a [Tool].
+	ToolInfo() Tool
 }
 
 // A Tool defines information about a tool used for running oscar's tasks. A Tool should be defined
@@ -28,8 +32,29 @@ type Tool struct {
 	RunArgs []string
 	// The path to the tool's config file, if it has one to use.
 	ConfigFilePath string
+	// Any other paths, relative to the project root, that the Task creates & removes on its own while
+	// it runs. Like a relative [Tool.ConfigFilePath], these never count as changes made during CI.
+	TempPaths []string
+	// The [Tasker.InfoText] values of any other Tasks in the same [TaskMap] group that must finish
+	// before this one is allowed to start. Note that this only affects ordering -- a Task will still
+	// run even if one of its dependencies failed, so that a single run reports as many failures as
+	// possible.
+	DependsOn []string
+	// Whether the Task's outcome depends on more than its project's files & oscar's own tooling (e.g.
+	// on the state of another Git branch), in which case its result must never be cached.
+	Uncacheable bool
+	// Whether the Task fixes what it finds by rewriting files (like a formatter), instead of only
+	// checking them. During CI, any such changes still fail the Task, but `oscar fix` runs only these
+	// Tasks and keeps their changes. So, a Mutating Task must only depend on other Mutating Tasks.
+	Mutating bool
+	// How long the Task may run before it's canceled, if it needs a different limit than
+	// [DefaultTaskTimeout]. Users can still override this via [Run.TaskTimeouts].
+	Timeout time.Duration
 }
 
+// ToolInfo implements [Tasker.ToolInfo].
+func (t Tool) ToolInfo() Tool { return t }
+
 // RenderRunCommandArgs uses [Tool.RunArgs] and does naive templating to replace certain values
 // before being used.
 //
@@ -64,3 +89,19 @@ func (tm TaskMap) SortedKeys() []string {
 
 	return keys
 }
+
+// Mutating returns a copy of the [TaskMap] with only its [Tool.Mutating] Tasks. Groups with no such
+// Tasks are left out entirely.
+func (tm TaskMap) Mutating() TaskMap {
+	out := make(TaskMap)
+	for lang, tasks := range tm {
+		mutating := slices.DeleteFunc(slices.Clone(tasks), func(t Tasker) bool {
+			return !t.ToolInfo().Mutating
+		})
+		if len(mutating) > 0 {
+			out[lang] = mutating
+		}
+	}
+
+	return out
+}
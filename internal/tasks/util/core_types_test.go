@@ -0,0 +1,22 @@
+package taskutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskMapMutating(t *testing.T) {
+	format := fakeTask{name: "Format", Tool: Tool{Mutating: true}}
+	imports := fakeTask{name: "Format imports", Tool: Tool{Mutating: true, DependsOn: []string{"Format"}}}
+	lint := fakeTask{name: "Lint"}
+
+	tm := TaskMap{
+		"Go":       {format, lint, imports},
+		"Markdown": {lint},
+	}
+
+	assert.Equal(t, TaskMap{"Go": {format, imports}}, tm.Mutating())
+	// The original is left as-is
+	assert.Len(t, tm["Go"], 3)
+}
@@ -0,0 +1,54 @@
+package taskutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// filterSeparator separates the language/tooling key from the Task name in a task filter, e.g.
+// "Go::Vulnerability scan".
+const filterSeparator = "::"
+
+// ValidateTaskFilter returns an error if the provided task filter is malformed. See
+// [RunOptions.Only] for the filter format.
+func ValidateTaskFilter(filter string) error {
+	lang, taskName, hasTask := strings.Cut(filter, filterSeparator)
+	if strings.TrimSpace(lang) == "" || (hasTask && strings.TrimSpace(taskName) == "") {
+		return fmt.Errorf("task filter '%s' must be of the form 'LANG' or 'LANG::TASK'", filter)
+	}
+
+	return nil
+}
+
+// matchesTaskFilter reports whether the provided task filter matches the Task, listed under lang in
+// its [TaskMap]. Matching ignores case, and any whitespace around each part.
+func matchesTaskFilter(filter string, lang string, task Tasker) bool {
+	filterLang, filterTask, hasTask := strings.Cut(filter, filterSeparator)
+	if !strings.EqualFold(strings.TrimSpace(filterLang), lang) {
+		return false
+	}
+
+	return !hasTask || strings.EqualFold(strings.TrimSpace(filterTask), task.InfoText())
+}
+
+// Skips reports whether the provided Task should be skipped, per [RunOptions.Only] and
+// [RunOptions.Skip].
+func (opts RunOptions) Skips(lang string, task Tasker) bool {
+	for _, filter := range opts.Skip {
+		if matchesTaskFilter(filter, lang, task) {
+			return true
+		}
+	}
+
+	if len(opts.Only) == 0 {
+		return false
+	}
+
+	for _, filter := range opts.Only {
+		if matchesTaskFilter(filter, lang, task) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,44 @@
+package taskutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkips(t *testing.T) {
+	build := fakeTask{name: "Build"}
+	vulnScan := fakeTask{name: "Vulnerability scan"}
+
+	tt := []struct {
+		Name     string
+		Opts     RunOptions
+		Lang     string
+		Task     Tasker
+		WantSkip bool
+	}{
+		{Name: "no filters", Opts: RunOptions{}, Lang: "Go", Task: build, WantSkip: false},
+		{Name: "only matching lang", Opts: RunOptions{Only: []string{"Go"}}, Lang: "Go", Task: build, WantSkip: false},
+		{Name: "only other lang", Opts: RunOptions{Only: []string{"Python"}}, Lang: "Go", Task: build, WantSkip: true},
+		{Name: "only other task", Opts: RunOptions{Only: []string{"Go::Build"}}, Lang: "Go", Task: vulnScan, WantSkip: true},
+		{Name: "skip task, with spacing & case", Opts: RunOptions{Skip: []string{"go :: vulnerability scan"}}, Lang: "Go", Task: vulnScan, WantSkip: true},
+		{Name: "skip other task", Opts: RunOptions{Skip: []string{"Go::Vulnerability scan"}}, Lang: "Go", Task: build, WantSkip: false},
+		{Name: "skip wins over only", Opts: RunOptions{Only: []string{"Go"}, Skip: []string{"Go::Build"}}, Lang: "Go", Task: build, WantSkip: true},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			assert.Equal(t, s.WantSkip, s.Opts.Skips(s.Lang, s.Task))
+		})
+	}
+}
+
+func TestValidateTaskFilter(t *testing.T) {
+	for _, filter := range []string{"Go", "Go::Build", " Go :: Build "} {
+		assert.NoError(t, ValidateTaskFilter(filter), filter)
+	}
+
+	for _, filter := range []string{"", "::Build", "Go::", " :: "} {
+		assert.Error(t, ValidateTaskFilter(filter), filter)
+	}
+}
@@ -0,0 +1,192 @@
+package taskutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensourcecorp/oscar/internal/consts"
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+)
+
+// keptRunLogDirs is how many of the most recent runs' log directories are kept. Older ones are
+// removed as new runs start.
+const keptRunLogDirs = 20
+
+// newRunLogDir creates & returns a new directory for the log files of a [Run] of the provided type,
+// under [consts.OscarRunLogDir]. Directories are named so that they sort by when their run started.
+func newRunLogDir(runType string) (string, error) {
+	if err := os.MkdirAll(consts.OscarRunLogDir, 0755); err != nil {
+		return "", fmt.Errorf("creating run log directory: %w", err)
+	}
+
+	prefix := time.Now().Format("20060102-150405") + "-" + logFileSlug(runType) + "-"
+	dir, err := os.MkdirTemp(consts.OscarRunLogDir, prefix)
+	if err != nil {
+		return "", fmt.Errorf("creating run log directory: %w", err)
+	}
+
+	if err := pruneRunLogDirs(consts.OscarRunLogDir, keptRunLogDirs); err != nil {
+		iprint.Warnf("removing old run logs: %v\n", err)
+	}
+
+	return dir, nil
+}
+
+// pruneRunLogDirs removes all but the newest `keep` run log directories in parent.
+func pruneRunLogDirs(parent string, keep int) error {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return err
+	}
+
+	dirs := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	slices.Sort(dirs)
+
+	var errs error
+	for len(dirs) > keep {
+		errs = errors.Join(errs, os.RemoveAll(filepath.Join(parent, dirs[0])))
+		dirs = dirs[1:]
+	}
+
+	return errs
+}
+
+// nonSlugChars matches runs of characters that aren't kept in a log file name.
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// logFileSlug returns the provided text as something safe to use in a file name, e.g. "Go :: Lint
+// (revive)" becomes "go-lint-revive".
+func logFileSlug(text string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
+
+// taskOutput collects the combined output of every command that a single Task runs (see
+// [system.WithOutput]). It's always kept in memory for the Task's [Result], and is also written to
+// the Task's log file if the [Run] has a [Run.LogDir], and printed as it comes in if
+// [RunOptions.Verbose] is set.
+type taskOutput struct {
+	buf     bytes.Buffer
+	logFile *os.File
+	live    *lineWriter
+	w       io.Writer
+}
+
+// newTaskOutput returns a [taskOutput] for the Task identified by id. If its log file can't be
+// created, a warning is printed, and its output is just not logged to a file.
+func (run Run) newTaskOutput(id string) *taskOutput {
+	out := &taskOutput{}
+	writers := []io.Writer{&out.buf}
+
+	if run.LogDir != "" {
+		logFile, err := os.Create(filepath.Join(run.LogDir, logFileSlug(id)+".log"))
+		if err != nil {
+			iprint.Warnf("creating log file for '%s': %v\n", id, err)
+		} else {
+			out.logFile = logFile
+			writers = append(writers, logFile)
+		}
+	}
+
+	if run.Options.Verbose {
+		out.live = &lineWriter{
+			prefix: run.Colors.Gray + "[" + id + "] " + run.Colors.Reset,
+			out:    os.Stdout,
+		}
+		writers = append(writers, out.live)
+	}
+
+	out.w = io.MultiWriter(writers...)
+
+	return out
+}
+
+// Write implements [io.Writer].
+func (o *taskOutput) Write(p []byte) (int, error) {
+	return o.w.Write(p)
+}
+
+// String returns everything written so far.
+func (o *taskOutput) String() string {
+	return o.buf.String()
+}
+
+// LogFilePath returns the path to the log file that the output is written to, if any.
+func (o *taskOutput) LogFilePath() string {
+	if o.logFile == nil {
+		return ""
+	}
+
+	return o.logFile.Name()
+}
+
+// Close prints any unfinished line of live output, and closes the log file.
+func (o *taskOutput) Close() error {
+	if o.live != nil {
+		o.live.Flush()
+	}
+	if o.logFile != nil {
+		if err := o.logFile.Close(); err != nil {
+			return fmt.Errorf("closing log file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// liveOutputMu keeps lines of live output from concurrently-running Tasks from being mixed together.
+var liveOutputMu sync.Mutex
+
+// lineWriter writes whole lines to out, each starting with prefix. Any unfinished line is held back
+// until it's finished, or until [lineWriter.Flush] is called.
+type lineWriter struct {
+	prefix  string
+	out     io.Writer
+	partial []byte
+}
+
+// Write implements [io.Writer]. Problems writing to out are ignored, since live output is only a
+// convenience, and shouldn't fail the Task.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i == -1 {
+			break
+		}
+		w.writeLine(w.partial[:i+1])
+		w.partial = w.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes any unfinished line.
+func (w *lineWriter) Flush() {
+	if len(w.partial) > 0 {
+		w.writeLine(append(w.partial, '\n'))
+		w.partial = nil
+	}
+}
+
+// writeLine writes a single line, with the prefix.
+func (w *lineWriter) writeLine(line []byte) {
+	liveOutputMu.Lock()
+	defer liveOutputMu.Unlock()
+
+	_, _ = io.WriteString(w.out, w.prefix)
+	_, _ = w.out.Write(line)
+}
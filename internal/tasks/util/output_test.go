@@ -0,0 +1,69 @@
+package taskutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFileSlug(t *testing.T) {
+	assert.Equal(t, "go-lint-revive", logFileSlug("Go :: Lint (revive)"))
+	assert.Equal(t, "svc-api-go-build", logFileSlug("svc/api :: Go :: Build"))
+}
+
+func TestLineWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &lineWriter{prefix: "[task] ", out: &out}
+
+	_, err := w.Write([]byte("one\ntw"))
+	require.NoError(t, err)
+	assert.Equal(t, "[task] one\n", out.String())
+
+	_, err = w.Write([]byte("o\nthree"))
+	require.NoError(t, err)
+	w.Flush()
+	assert.Equal(t, "[task] one\n[task] two\n[task] three\n", out.String())
+}
+
+func TestTaskOutput(t *testing.T) {
+	run := Run{LogDir: t.TempDir()}
+
+	output := run.newTaskOutput("Go :: Build")
+	_, err := fmt.Fprint(output, "building\n")
+	require.NoError(t, err)
+	require.NoError(t, output.Close())
+
+	assert.Equal(t, "building\n", output.String())
+	assert.Equal(t, filepath.Join(run.LogDir, "go-build.log"), output.LogFilePath())
+
+	logged, err := os.ReadFile(output.LogFilePath())
+	require.NoError(t, err)
+	assert.Equal(t, "building\n", string(logged))
+
+	// Without a log directory, output is only kept in memory
+	output = Run{}.newTaskOutput("Go :: Build")
+	assert.Empty(t, output.LogFilePath())
+	require.NoError(t, output.Close())
+}
+
+func TestPruneRunLogDirs(t *testing.T) {
+	parent := t.TempDir()
+	for _, name := range []string{"20250103-ci-a", "20250101-ci-b", "20250102-deliver-c"} {
+		require.NoError(t, os.Mkdir(filepath.Join(parent, name), 0755))
+	}
+
+	require.NoError(t, pruneRunLogDirs(parent, 2))
+
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	names := make([]string, 0)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"20250102-deliver-c", "20250103-ci-a"}, names)
+}
@@ -0,0 +1,178 @@
+package taskutil
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+// RootProject is the [Project.Root] of the project at the root of the repo.
+const RootProject = "."
+
+// projectMarkerFiles maps the names of files that mark the directory they're in as a project root,
+// to the file type (see [system.FileIsOfType]) that the project is for. A marker file only counts if
+// there are also files of that type in its directory tree, so that e.g. a `pyproject.toml` that just
+// holds tool config doesn't make a project out of a directory with no Python in it. Files of these
+// types only ever belong to a project with their own marker file (or to [RootProject]), since their
+// tools need it -- e.g. Go files in a Python project without a `go.mod` belong to the enclosing Go
+// project instead.
+var projectMarkerFiles = map[string]string{
+	"go.mod":         "go",
+	"pyproject.toml": "py",
+}
+
+// A Project is a single directory tree within the repo that Tasks are run against on its own, e.g. a
+// nested Go module in a monorepo.
+type Project struct {
+	// The slash-separated path to the project's root directory, relative to the repo root. This is
+	// [RootProject] for the repo root itself.
+	Root string
+	// The contents of the project, with every path relative to Root. Files that belong to a project
+	// nested further down are not included, so that no file is checked more than once.
+	Repo Repo
+}
+
+// ProjectTaskMap aliases a map of a [Project.Root] to the [TaskMap] to run for that project.
+type ProjectTaskMap map[string]TaskMap
+
+// SortedKeys sorts the keys of the [ProjectTaskMap], with [RootProject] always first. Useful for
+// iterating through projects in a predictable order during runs.
+func (ptm ProjectTaskMap) SortedKeys() []string {
+	keys := make([]string, 0)
+	for key := range ptm {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, compareProjectRoots)
+
+	return keys
+}
+
+// compareProjectRoots orders project roots alphabetically, except that [RootProject] is always
+// first.
+func compareProjectRoots(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == RootProject:
+		return -1
+	case b == RootProject:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// NewProjects splits the provided [Repo] into a [Project] for the repo root, plus one for every
+// directory that has a project marker file (like a `go.mod` or `pyproject.toml`, see
+// [projectMarkerFiles]) or that is listed in declaredRoots. Each file in the [Repo] is assigned to
+// the deepest project that contains it, and that has the marker file for its type, if any.
+func NewProjects(repo Repo, declaredRoots []string) ([]Project, error) {
+	roots := []string{RootProject}
+
+	for _, root := range declaredRoots {
+		cleaned := path.Clean(strings.TrimSpace(root))
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return nil, fmt.Errorf("project root '%s' must be a path inside the repo", root)
+		}
+		if !slices.Contains(roots, cleaned) {
+			roots = append(roots, cleaned)
+		}
+	}
+
+	for _, file := range repo.Files {
+		fileType, isMarker := projectMarkerFiles[path.Base(file)]
+		root := path.Dir(file)
+		if !isMarker || slices.Contains(roots, root) {
+			continue
+		}
+
+		hasFilesOfType := slices.ContainsFunc(repo.Files, func(f string) bool {
+			return strings.HasPrefix(f, root+"/") && system.FileIsOfType(f, fileType)
+		})
+		if hasFilesOfType {
+			roots = append(roots, root)
+		}
+	}
+
+	slices.SortFunc(roots, compareProjectRoots)
+
+	// The roots that have each marked file type's marker file
+	markedRoots := make(map[string][]string)
+	for _, file := range repo.Files {
+		fileType, isMarker := projectMarkerFiles[path.Base(file)]
+		if root := path.Dir(file); isMarker && slices.Contains(roots, root) {
+			markedRoots[fileType] = append(markedRoots[fileType], root)
+		}
+	}
+
+	projects := make([]Project, len(roots))
+	indexes := make(map[string]int)
+	for i, root := range roots {
+		projects[i] = Project{Root: root, Repo: Repo{Files: make([]string, 0)}}
+		if repo.ChangedOnly() {
+			projects[i].Repo.ChangedFiles = make([]string, 0)
+		}
+		indexes[root] = i
+	}
+
+	for _, file := range repo.Files {
+		p := &projects[indexes[projectRootForFile(roots, markedRoots, file)]]
+		p.Repo.Files = append(p.Repo.Files, relativeToProject(p.Root, file))
+	}
+	for _, file := range repo.ChangedFiles {
+		p := &projects[indexes[projectRootForFile(roots, markedRoots, file)]]
+		p.Repo.ChangedFiles = append(p.Repo.ChangedFiles, relativeToProject(p.Root, file))
+	}
+
+	var errs error
+	for i := range projects {
+		if projects[i].Root != RootProject && len(projects[i].Repo.Files) == 0 {
+			errs = errors.Join(errs, fmt.Errorf("project root '%s' has no files in it", projects[i].Root))
+		}
+		projects[i].Repo.setFileTypes()
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	return projects, nil
+}
+
+// projectRootForFile returns the deepest of the provided project roots that contains the file. If
+// the file is of a type that has a project marker file (see [projectMarkerFiles]), only the roots in
+// markedRoots for that type are considered.
+func projectRootForFile(roots []string, markedRoots map[string][]string, file string) string {
+	candidates := roots
+	for _, fileType := range projectMarkerFiles {
+		if system.FileIsOfType(file, fileType) {
+			candidates = markedRoots[fileType]
+			break
+		}
+	}
+
+	out := RootProject
+	for _, root := range candidates {
+		if root == RootProject || !strings.HasPrefix(file, root+"/") {
+			continue
+		}
+		if out == RootProject || len(root) > len(out) {
+			out = root
+		}
+	}
+
+	return out
+}
+
+// relativeToProject returns the repo-relative file as a path relative to the project root.
+func relativeToProject(root string, file string) string {
+	if root == RootProject {
+		return file
+	}
+
+	return strings.TrimPrefix(file, root+"/")
+}
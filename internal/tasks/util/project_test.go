@@ -0,0 +1,117 @@
+package taskutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProjects(t *testing.T) {
+	repo := Repo{
+		Files: []string{
+			".github/workflows/main.yml",
+			"README.md",
+			"go.mod",
+			"main.go",
+			"infra/prod/main.tf",
+			"libs/util/go.mod",
+			"libs/util/util.go",
+			"services/api/pyproject.toml",
+			"services/api/src/app.py",
+			"services/api/tools/go.mod",
+			"services/api/tools/gen.go",
+			// Not a project, since there's no Python here
+			"tools/config/pyproject.toml",
+		},
+		ChangedFiles: []string{"main.go", "services/api/src/app.py"},
+	}
+
+	projects, err := NewProjects(repo, []string{"infra/prod/"})
+	require.NoError(t, err)
+
+	roots := make([]string, 0)
+	for _, p := range projects {
+		roots = append(roots, p.Root)
+	}
+	assert.Equal(t, []string{".", "infra/prod", "libs/util", "services/api", "services/api/tools"}, roots)
+
+	// Files belong only to their deepest project, relative to its root
+	assert.Equal(
+		t,
+		[]string{".github/workflows/main.yml", "README.md", "go.mod", "main.go", "tools/config/pyproject.toml"},
+		projects[0].Repo.Files,
+	)
+	assert.Equal(t, []string{"main.tf"}, projects[1].Repo.Files)
+	assert.Equal(t, []string{"pyproject.toml", "src/app.py"}, projects[3].Repo.Files)
+	assert.Equal(t, []string{"go.mod", "gen.go"}, projects[4].Repo.Files)
+
+	// Changed files are split up the same way, and file types only consider them
+	assert.Equal(t, []string{"main.go"}, projects[0].Repo.ChangedFiles)
+	assert.Equal(t, []string{}, projects[2].Repo.ChangedFiles)
+	assert.True(t, projects[0].Repo.HasGo)
+	assert.False(t, projects[2].Repo.HasGo)
+	assert.True(t, projects[3].Repo.HasPython)
+	assert.False(t, projects[4].Repo.HasGo)
+}
+
+func TestNewProjectsMarkedFileTypes(t *testing.T) {
+	repo := Repo{
+		Files: []string{
+			"go.mod",
+			"main.go",
+			"services/api/pyproject.toml",
+			"services/api/app.py",
+			// No go.mod here, so these belong to the root Go module
+			"services/api/bindings.go",
+			"services/api/tools/gen.go",
+			// A declared root without a go.mod
+			"scripts/run.sh",
+			"scripts/helper.go",
+		},
+	}
+
+	projects, err := NewProjects(repo, []string{"scripts"})
+	require.NoError(t, err)
+	require.Len(t, projects, 3)
+
+	assert.Equal(t, ".", projects[0].Root)
+	assert.Equal(
+		t,
+		[]string{"go.mod", "main.go", "services/api/bindings.go", "services/api/tools/gen.go", "scripts/helper.go"},
+		projects[0].Repo.Files,
+	)
+	assert.True(t, projects[0].Repo.HasGo)
+
+	assert.Equal(t, "scripts", projects[1].Root)
+	assert.Equal(t, []string{"run.sh"}, projects[1].Repo.Files)
+	assert.False(t, projects[1].Repo.HasGo)
+
+	assert.Equal(t, "services/api", projects[2].Root)
+	assert.Equal(t, []string{"pyproject.toml", "app.py"}, projects[2].Repo.Files)
+	assert.True(t, projects[2].Repo.HasPython)
+	assert.False(t, projects[2].Repo.HasGo)
+}
+
+func TestNewProjectsErrors(t *testing.T) {
+	tt := []struct {
+		Name          string
+		DeclaredRoots []string
+	}{
+		{Name: "outside the repo", DeclaredRoots: []string{"../other"}},
+		{Name: "absolute", DeclaredRoots: []string{"/tmp"}},
+		{Name: "no files", DeclaredRoots: []string{"does/not/exist"}},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			_, err := NewProjects(Repo{Files: []string{"main.go"}}, s.DeclaredRoots)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestProjectTaskMapSortedKeys(t *testing.T) {
+	ptm := ProjectTaskMap{"b": nil, "-a": nil, RootProject: nil, "a": nil}
+	assert.Equal(t, []string{".", "-a", "a", "b"}, ptm.SortedKeys())
+}
@@ -3,7 +3,11 @@ package taskutil
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
 
+	igit "github.com/opensourcecorp/oscar/internal/git"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 	"github.com/opensourcecorp/oscar/internal/system"
 )
@@ -17,13 +21,76 @@ type Repo struct {
 	HasContainerfile bool
 	HasYaml          bool
 	HasMarkdown      bool
+	HasProtobuf      bool
+	// Every file in the repo, minus any ignored by Git. See [system.ListFiles].
+	Files []string
+	// If not nil, the run is limited to only these files (see [RunOptions.ChangedSince] &
+	// [RunOptions.Staged]), and the Has* fields only consider these files.
+	ChangedFiles []string
+}
+
+// ChangedOnly reports whether the run is limited to the files in [Repo.ChangedFiles].
+func (repo Repo) ChangedOnly() bool {
+	return repo.ChangedFiles != nil
+}
+
+// FilesOfType returns the files that Tasks should run against that are of the provided file type,
+// i.e. from [Repo.ChangedFiles] if the run is limited to those, or otherwise from [Repo.Files]. See
+// [system.FileIsOfType] for the file type names.
+func (repo Repo) FilesOfType(fileType string) []string {
+	if repo.ChangedOnly() {
+		return system.FilesOfType(repo.ChangedFiles, fileType)
+	}
+
+	return system.FilesOfType(repo.Files, fileType)
+}
+
+// FilesMatching returns the files that Tasks should run against that match any of the provided
+// glob patterns, i.e. from [Repo.ChangedFiles] if the run is limited to those, or otherwise from
+// [Repo.Files]. See [system.FilesMatching] for the pattern format.
+func (repo Repo) FilesMatching(patterns []string) ([]string, error) {
+	if repo.ChangedOnly() {
+		return system.FilesMatching(repo.ChangedFiles, patterns)
+	}
+
+	return system.FilesMatching(repo.Files, patterns)
+}
+
+// GoPackages returns the Go package patterns that package-scoped Go tools should run against. This
+// is every package ("./..."), unless the run is limited to changed files, in which case it is only
+// the packages that contain a changed Go file.
+func (repo Repo) GoPackages() []string {
+	if !repo.ChangedOnly() {
+		return []string{"./..."}
+	}
+
+	out := make([]string, 0)
+	for _, file := range repo.FilesOfType("go") {
+		pkg := "."
+		if dir := filepath.Dir(file); dir != "." {
+			pkg = "./" + filepath.ToSlash(dir)
+		}
+		if !slices.Contains(out, pkg) {
+			out = append(out, pkg)
+		}
+	}
+	slices.Sort(out)
+
+	return out
 }
 
 // String implements the [fmt.Stringer] interface.
 func (repo Repo) String() string {
 	var out string
 
-	out += "The following file types were found in this repo, and tasks will be run against them:\n"
+	if repo.ChangedOnly() {
+		out += fmt.Sprintf(
+			"Only running against the %d changed file(s), which are of the following types:\n",
+			len(repo.ChangedFiles),
+		)
+	} else {
+		out += "The following file types were found in this repo, and tasks will be run against them:\n"
+	}
 
 	if repo.HasGo {
 		out += "- Go\n"
@@ -46,6 +113,9 @@ func (repo Repo) String() string {
 	if repo.HasMarkdown {
 		out += "- Markdown\n"
 	}
+	if repo.HasProtobuf {
+		out += "- Protobuf\n"
+	}
 
 	// One more newline for padding
 	out += "\n"
@@ -53,59 +123,46 @@ func (repo Repo) String() string {
 	return out
 }
 
-// NewRepo returns a populated [Repo].
-func NewRepo(ctx context.Context) (Repo, error) {
-	var errs error
-
-	hasGo, err := system.FilesExistInTree(ctx, system.GetFileTypeListerCommand("go"))
+// NewRepo returns a populated [Repo]. The [Repo] is limited to the files that have changed since
+// [RunOptions.ChangedSince] if set (see [igit.ChangedFilesSince]), or to the staged files if
+// [RunOptions.Staged] is set (see [igit.StagedFiles]).
+func NewRepo(ctx context.Context, opts RunOptions) (Repo, error) {
+	files, err := system.ListFiles(ctx, ".")
 	if err != nil {
-		errs = errors.Join(errs, err)
+		return Repo{}, err
 	}
 
-	hasPython, err := system.FilesExistInTree(ctx, system.GetFileTypeListerCommand("py"))
-	if err != nil {
-		errs = errors.Join(errs, err)
-	}
-
-	hasShell, err := system.FilesExistInTree(ctx, system.GetFileTypeListerCommand("sh"))
-	if err != nil {
-		errs = errors.Join(errs, err)
-	}
-
-	hasTerraform, err := system.FilesExistInTree(ctx, system.GetFileTypeListerCommand("tf"))
-	if err != nil {
-		errs = errors.Join(errs, err)
-	}
-
-	hasContainerfile, err := system.FilesExistInTree(ctx, system.GetFileTypeListerCommand("containerfile"))
-	if err != nil {
-		errs = errors.Join(errs, err)
-	}
-
-	hasYaml, err := system.FilesExistInTree(ctx, system.GetFileTypeListerCommand("yaml"))
-	if err != nil {
-		errs = errors.Join(errs, err)
-	}
-
-	hasMarkdown, err := system.FilesExistInTree(ctx, system.GetFileTypeListerCommand("md"))
-	if err != nil {
-		errs = errors.Join(errs, err)
-	}
+	repo := Repo{Files: files}
 
-	if errs != nil {
-		return Repo{}, errs
+	switch {
+	case opts.ChangedSince != "" && opts.Staged:
+		return Repo{}, errors.New("cannot limit a run to both staged files and files changed since a ref")
+	case opts.ChangedSince != "":
+		repo.ChangedFiles, err = igit.ChangedFilesSince(ctx, opts.ChangedSince)
+		if err != nil {
+			return Repo{}, err
+		}
+	case opts.Staged:
+		repo.ChangedFiles, err = igit.StagedFiles(ctx)
+		if err != nil {
+			return Repo{}, err
+		}
 	}
 
-	repo := Repo{
-		HasGo:            hasGo,
-		HasPython:        hasPython,
-		HasShell:         hasShell,
-		HasTerraform:     hasTerraform,
-		HasContainerfile: hasContainerfile,
-		HasYaml:          hasYaml,
-		HasMarkdown:      hasMarkdown,
-	}
+	repo.setFileTypes()
 	iprint.Debugf("repo composition: %+v\n", repo)
 
 	return repo, nil
 }
+
+// setFileTypes sets the Has* fields of the [Repo] based on the files that Tasks would run against.
+func (repo *Repo) setFileTypes() {
+	repo.HasGo = len(repo.FilesOfType("go")) > 0
+	repo.HasPython = len(repo.FilesOfType("py")) > 0
+	repo.HasShell = len(repo.FilesOfType("sh")) > 0
+	repo.HasTerraform = len(repo.FilesOfType("tf")) > 0
+	repo.HasContainerfile = len(repo.FilesOfType("containerfile")) > 0
+	repo.HasYaml = len(repo.FilesOfType("yaml")) > 0
+	repo.HasMarkdown = len(repo.FilesOfType("md")) > 0
+	repo.HasProtobuf = len(repo.FilesOfType("protobuf")) > 0
+}
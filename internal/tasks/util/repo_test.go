@@ -0,0 +1,61 @@
+package taskutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoPackages(t *testing.T) {
+	tt := []struct {
+		Name         string
+		ChangedFiles []string
+		Want         []string
+	}{
+		{
+			Name:         "whole tree",
+			ChangedFiles: nil,
+			Want:         []string{"./..."},
+		},
+		{
+			Name:         "changed files",
+			ChangedFiles: []string{"main.go", "internal/a/a.go", "internal/a/a_test.go", "internal/b/b.go", "README.md"},
+			Want:         []string{".", "./internal/a", "./internal/b"},
+		},
+		{
+			Name:         "no changed Go files",
+			ChangedFiles: []string{"README.md"},
+			Want:         []string{},
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			repo := Repo{ChangedFiles: s.ChangedFiles}
+			assert.Equal(t, s.Want, repo.GoPackages())
+		})
+	}
+}
+
+func TestFilesOfType(t *testing.T) {
+	repo := Repo{Files: []string{
+		"Containerfile",
+		"build/app.Dockerfile",
+		"docs/dockerfile-notes.md",
+		"docs/index.md",
+		"scripts/run.sh",
+		".github/workflows/main.yml",
+		"config.yaml",
+	}}
+
+	assert.Equal(t, []string{"Containerfile", "build/app.Dockerfile"}, repo.FilesOfType("containerfile"))
+	assert.Equal(t, []string{"docs/dockerfile-notes.md", "docs/index.md"}, repo.FilesOfType("md"))
+	assert.Equal(t, []string{"scripts/run.sh"}, repo.FilesOfType("sh"))
+	assert.Equal(t, []string{".github/workflows/main.yml", "config.yaml"}, repo.FilesOfType("yaml"))
+	assert.Empty(t, repo.FilesOfType("py"))
+
+	// Runs limited to changed files only see those
+	repo.ChangedFiles = []string{"config.yaml"}
+	assert.Equal(t, []string{"config.yaml"}, repo.FilesOfType("yaml"))
+	assert.Empty(t, repo.FilesOfType("md"))
+}
@@ -4,10 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/opensourcecorp/oscar/internal/cache"
+	"github.com/opensourcecorp/oscar/internal/consts"
+	oscarcfgpbv1 "github.com/opensourcecorp/oscar/internal/generated/opensourcecorp/oscar/config/v1"
 	igit "github.com/opensourcecorp/oscar/internal/git"
+	"github.com/opensourcecorp/oscar/internal/oscarcfg"
 	iprint "github.com/opensourcecorp/oscar/internal/print"
 	"github.com/opensourcecorp/oscar/internal/system"
 )
@@ -22,16 +30,111 @@ type Run struct {
 	Git *igit.Git
 	// See [Repo].
 	Repo Repo
+	// The [Repo], split up per [Project]. See [NewProjects].
+	Projects []Project
 	// See [iprint.AllColors].
 	Colors iprint.AllColors
 	// A timestamp for storing when the overall run started.
 	StartTime time.Time
 	// Keeps track of all task failures.
 	Failures []string
+	// Keeps track of all Tasks that were skipped, per [RunOptions.Only] & [RunOptions.Skip].
+	Skipped []string
+	// See [RunOptions].
+	Options RunOptions
+	// If set, Tasks that already passed with the same inputs in an earlier run are not run again. See
+	// [Run.Execute].
+	Cache *cache.Cache
+	// How long Tasks matching each task filter (in the same format as [RunOptions.Only]) may run,
+	// from the oscar config file. A zero duration means no timeout. See [Run.taskTimeout].
+	TaskTimeouts map[string]time.Duration
+	// If set, the directory that each Task's output is logged to, in its own file. See
+	// [Result.LogFile].
+	LogDir string
+}
+
+// RunOptions holds caller-provided settings for a [Run].
+type RunOptions struct {
+	// The maximum number of Tasks that [Run.Execute] may run at the same time.
+	Jobs int
+	// Task filters to run exclusively, each either a [TaskMap] key (e.g. "Go") to match every Task
+	// listed under it, or a key and a Task's InfoText, separated by "::" (e.g. "Go::Build"). If
+	// empty, every Task is run (unless listed in Skip). Filters apply to matching Tasks in every
+	// [Project].
+	Only []string
+	// Task filters to skip, in the same format as Only. Skip takes precedence over Only.
+	Skip []string
+	// If set, a Git ref to limit the run to -- only files that have changed since this ref are
+	// checked, where supported. See [Repo.ChangedFiles].
+	ChangedSince string
+	// Whether to limit the run to only the files that are staged in the Git index, e.g. for a
+	// pre-commit hook. Any unstaged changes are set aside while the run's tasks check the staged
+	// contents of those files. Can't be used along with ChangedSince. See [Repo.ChangedFiles].
+	Staged bool
+	// If set, each finished [Run] is added to this [Recorder].
+	Recorder *Recorder
+	// Whether to ignore any cached Task results, and run every Task. See [Run.Cache].
+	NoCache bool
+	// The maximum number of lines to print of the diff of any files that a CI Task changed. Zero
+	// means no limit.
+	DiffMaxLines int
+	// If set, a patch of every change that CI Tasks made to files is written to this path, which
+	// `git apply` can use to make the same changes.
+	PatchFile string
+	// Whether to run only the CI Tasks that fix problems by rewriting files, keeping their changes
+	// instead of failing. See [Tool.Mutating].
+	Fix bool
+	// Whether to print the output of every Task's commands as it comes in, instead of only showing
+	// the output of Tasks that fail.
+	Verbose bool
+}
+
+// A RunRecord holds the results of a finished [Run], e.g. for writing reports.
+type RunRecord struct {
+	// See [Run.Type].
+	Type string
+	// When the run started.
+	StartTime time.Time
+	// When the run finished.
+	EndTime time.Time
+	// The results of every Task in the run.
+	Results []Result
+}
+
+// A Recorder collects a [RunRecord] for every [Run] in a single oscar invocation, e.g. both the CI &
+// Delivery runs for the `deliver` subcommand.
+type Recorder struct {
+	mu      sync.Mutex
+	records []RunRecord
+}
+
+// Add records the results of a [Run]. It is safe to call on a nil [Recorder], which does nothing.
+func (r *Recorder) Add(run Run, results []Result) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, RunRecord{
+		Type:      run.Type,
+		StartTime: run.StartTime,
+		EndTime:   time.Now(),
+		Results:   results,
+	})
+}
+
+// Records returns every [RunRecord] added so far.
+func (r *Recorder) Records() []RunRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.records)
 }
 
 // NewRun returns a populated [Run].
-func NewRun(ctx context.Context, runType string) (Run, error) {
+func NewRun(ctx context.Context, runType string, opts RunOptions) (Run, error) {
 	// Kind of wonky, but print the banner first
 	Run{Type: runType}.PrintRunTypeBanner()
 
@@ -48,22 +151,69 @@ func NewRun(ctx context.Context, runType string) (Run, error) {
 	}
 	iprint.Infof(colors.Gray + git.String() + colors.Reset)
 
-	repo, err := NewRepo(ctx)
+	repo, err := NewRepo(ctx, opts)
 	if err != nil {
 		return Run{}, fmt.Errorf("getting repo composition: %w", err)
 	}
 	iprint.Infof(colors.Gray + repo.String() + colors.Reset)
 
+	cfg, err := optionalConfig()
+	if err != nil {
+		return Run{}, err
+	}
+	taskTimeouts, err := parseTaskTimeouts(cfg.GetTaskTimeouts())
+	if err != nil {
+		return Run{}, fmt.Errorf("oscar config file task_timeouts: %w", err)
+	}
+	projects, err := NewProjects(repo, cfg.GetProjects())
+	if err != nil {
+		return Run{}, fmt.Errorf("finding projects in repo: %w", err)
+	}
+	logDir, err := newRunLogDir(runType)
+	if err != nil {
+		// Logs are only a convenience, so they shouldn't stop the run
+		iprint.Warnf("not writing task log files: %v\n", err)
+	}
+
+	if len(projects) > 1 {
+		iprint.Infof(colors.Gray + "The following project roots were found, and tasks will be run in each:\n")
+		for _, p := range projects {
+			iprint.Infof("- %s\n", p.Root)
+		}
+		iprint.Infof("\n" + colors.Reset)
+	}
+
 	return Run{
-		Type:      runType,
-		Git:       git,
-		Repo:      repo,
-		Colors:    colors,
-		StartTime: time.Now(),
-		Failures:  make([]string, 0),
+		Type:         runType,
+		Git:          git,
+		Repo:         repo,
+		Projects:     projects,
+		Colors:       colors,
+		StartTime:    time.Now(),
+		Failures:     make([]string, 0),
+		Skipped:      make([]string, 0),
+		Options:      opts,
+		TaskTimeouts: taskTimeouts,
+		LogDir:       logDir,
 	}, nil
 }
 
+// optionalConfig returns the oscar config file's contents, for the settings that a [Run] uses from
+// it. The config file is optional for those, so if there isn't one, it returns nil, which reads as
+// an empty config.
+func optionalConfig() (*oscarcfgpbv1.Config, error) {
+	if _, err := os.Stat(consts.DefaultOscarCfgFileName); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	cfg, err := oscarcfg.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting oscar config: %w", err)
+	}
+
+	return cfg, nil
+}
+
 // PrintRunTypeBanner prints a banner about the type of [Run] underway.
 func (run Run) PrintRunTypeBanner() {
 	colors := iprint.Colors()
@@ -87,7 +237,12 @@ func (run Run) PrintRunTypeBanner() {
 
 // PrintTaskMapBanner prints a banner about the [TaskMap] being run.
 func (run Run) PrintTaskMapBanner(lang string) {
-	iprint.Infof("=== %s %s>\n", lang, strings.Repeat("=", 64-len(lang)))
+	iprint.Infof("=== %s %s>\n", lang, padding("=", lang, 64))
+}
+
+// PrintProjectBanner prints a banner about the [Project] whose Tasks are being run.
+func (run Run) PrintProjectBanner(root string) {
+	iprint.Infof("\n### Project: %s %s#\n\n", root, strings.Repeat("#", max(54-len(root), 1)))
 }
 
 // PrintTaskBanner prints a banner about the Task being run.
@@ -96,25 +251,57 @@ func (run Run) PrintTaskBanner(task Tasker) {
 	iprint.Infof(
 		"> %s %s............",
 		iprint.Colors().White+task.InfoText()+iprint.Colors().InfoColor,
-		strings.Repeat(".", 32-len(task.InfoText())),
+		padding(".", task.InfoText(), 32),
 	)
 }
 
+// padding returns char repeated enough times to line text up to width characters, and at least
+// once, so that text longer than width (like a long custom task name) still gets some.
+func padding(char string, text string, width int) string {
+	return strings.Repeat(char, max(width-utf8.RuneCountInString(text), 1))
+}
+
 // ReportSuccess prints information about the success of a [Run].
 func (run Run) ReportSuccess() {
-	iprint.Goodf("\nAll tasks succeeded! (%s)\n\n", iprint.RunDurationString(run.StartTime))
+	run.reportSkipped()
+	iprint.Goodf("\nAll tasks succeeded! (%s)\n", iprint.RunDurationString(run.StartTime))
+	run.reportLogDir()
+	iprint.Infof("\n")
+}
+
+// reportLogDir prints where every Task's log file is, if they were written.
+func (run Run) reportLogDir() {
+	if run.LogDir != "" {
+		iprint.Infof(run.Colors.Gray+"Output from every task is logged under '%s'\n"+run.Colors.Reset, run.LogDir)
+	}
+}
+
+// reportSkipped prints any Tasks that were skipped during the [Run], so that skipping a Task is
+// never silent.
+func (run Run) reportSkipped() {
+	if len(run.Skipped) == 0 {
+		return
+	}
+
+	iprint.Infof(run.Colors.WarnColor + "\nThe following tasks were SKIPPED:\n" + run.Colors.Reset)
+	for _, s := range run.Skipped {
+		iprint.Infof(run.Colors.WarnColor+"- %s\n"+run.Colors.Reset, s)
+	}
 }
 
 // ReportFailure prints information about the failure of a [Run]. It takes an `error` arg in case
 // the caller is expecting to return a joined error because of e.g. deferred calls or later-checked
 // errors that an outer variable already holds.
 func (run Run) ReportFailure(err error) error {
+	run.reportSkipped()
 	iprint.Errorf("\n%s\n", strings.Repeat("=", 65))
 	iprint.Errorf("The following tasks failed: (%s)\n", iprint.RunDurationString(run.StartTime))
 	for _, f := range run.Failures {
 		iprint.Errorf("- %s\n", f)
 	}
-	iprint.Errorf("%s\n\n", strings.Repeat("=", 65))
+	iprint.Errorf("%s\n", strings.Repeat("=", 65))
+	run.reportLogDir()
+	iprint.Errorf("\n")
 
 	err = errors.Join(err, errors.New("one or more tasks failed"))
 	return err
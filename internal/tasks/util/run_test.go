@@ -0,0 +1,34 @@
+package taskutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPadding(t *testing.T) {
+	tt := []struct {
+		Name string
+		Text string
+		Want int
+	}{
+		{Name: "ASCII", Text: "Lint (revive)", Want: 19},
+		// 20 characters, but 60 bytes
+		{Name: "multi-byte", Text: strings.Repeat("検", 20), Want: 12},
+		{Name: "longer than width", Text: strings.Repeat("x", 40), Want: 1},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			assert.Equal(t, strings.Repeat(".", s.Want), padding(".", s.Text, 32))
+		})
+	}
+}
+
+func TestPrintTaskBannerMultiByteName(t *testing.T) {
+	assert.NotPanics(t, func() {
+		Run{}.PrintTaskBanner(fakeTask{name: strings.Repeat("検", 32)})
+		Run{}.PrintTaskMapBanner(strings.Repeat("検", 32))
+	})
+}
@@ -0,0 +1,400 @@
+package taskutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+	"github.com/opensourcecorp/oscar/internal/system"
+)
+
+// Status is the final state of a Task in a [Run].
+type Status string
+
+const (
+	// StatusPassed is for Tasks that succeeded.
+	StatusPassed Status = "PASSED"
+	// StatusFailed is for Tasks that failed.
+	StatusFailed Status = "FAILED"
+	// StatusSkipped is for Tasks that were not run, per [RunOptions.Only] & [RunOptions.Skip].
+	StatusSkipped Status = "SKIPPED"
+	// StatusTimedOut is for Tasks that failed because they ran for longer than their timeout. See
+	// [Run.taskTimeout].
+	StatusTimedOut Status = "TIMED OUT"
+)
+
+// A Result holds the outcome of a single Task that was run via [Run.Execute].
+type Result struct {
+	// The [Project.Root] of the project that the Task was run for.
+	Project string
+	// The [TaskMap] key that the Task was listed under.
+	Lang string
+	// The Task itself.
+	Task Tasker
+	// When the Task started.
+	StartTime time.Time
+	// When the Task (including any [TaskHooks.Finish] checks) finished.
+	EndTime time.Time
+	// See [Status].
+	Status Status
+	// Any error returned from the Task's Exec or Post calls, or from [TaskHooks.Finish].
+	Err error
+	// The combined output of every command that the Task ran.
+	Output string
+	// The path to the file that Output was logged to, if any. See [Run.LogDir].
+	LogFile string
+	// Whether the Task was not actually run, because it already passed with the same inputs in an
+	// earlier run. See [Run.Cache].
+	Cached bool
+}
+
+// ID returns the Task's identifier for the run. See [TaskID].
+func (r Result) ID() string { return TaskID(r.Project, r.Lang, r.Task) }
+
+// Duration returns how long the Task took to run.
+func (r Result) Duration() time.Duration { return r.EndTime.Sub(r.StartTime) }
+
+// FailureText returns a line about the failed Task for a run's summary, e.g. "Go :: Build (log:
+// /path/to/go-build.log)".
+func (r Result) FailureText() string {
+	out := r.ID()
+	if r.Status == StatusTimedOut {
+		out += " (" + string(StatusTimedOut) + ")"
+	}
+	if r.LogFile != "" {
+		out += " (log: " + r.LogFile + ")"
+	}
+
+	return out
+}
+
+// TaskID returns the string used to identify a Task across a run, e.g. "Go :: Build". Tasks for any
+// project other than [RootProject] are prefixed with that project's root, e.g. "svc/api :: Go ::
+// Build".
+func TaskID(project string, lang string, task Tasker) string {
+	if project == "" || project == RootProject {
+		return fmt.Sprintf("%s :: %s", lang, task.InfoText())
+	}
+
+	return fmt.Sprintf("%s :: %s :: %s", project, lang, task.InfoText())
+}
+
+// TaskHooks lets a caller of [Run.Execute] add its own behavior around every Task. Any field may be
+// left nil.
+type TaskHooks struct {
+	// Start is called right before a Task's Exec, with whether the Task rewrites files (see
+	// [Tool.Mutating]).
+	Start func(id string, mutating bool)
+	// Finish is called after a Task's Exec & Post have returned. Any error it returns fails the
+	// Task.
+	Finish func(ctx context.Context, id string) error
+}
+
+// node is a single Task in the dependency graph that [Run.Execute] walks.
+type node struct {
+	project string
+	lang    string
+	task    Tasker
+	// Indexes of the nodes that depend on this one.
+	dependents []int
+	// How many of this node's dependencies have not yet finished.
+	waitingOn int
+}
+
+// buildGraph returns every Task in the [ProjectTaskMap] as a node, in the same order that a
+// sequential run would use (sorted by project, then by key, then by list order), with each node's
+// dependency edges filled in. It returns an error if a Task depends on a Task that doesn't exist in
+// its group, or if the dependencies form a cycle.
+func buildGraph(projectTaskMap ProjectTaskMap) ([]*node, error) {
+	nodes := make([]*node, 0)
+	// group index lookups, keyed by project & lang (see groupKey), and then InfoText
+	indexes := make(map[string]map[string]int)
+	groupKey := func(project string, lang string) string { return project + "\x00" + lang }
+
+	for _, project := range projectTaskMap.SortedKeys() {
+		taskMap := projectTaskMap[project]
+		for _, lang := range taskMap.SortedKeys() {
+			group := groupKey(project, lang)
+			indexes[group] = make(map[string]int)
+			for _, task := range taskMap[lang] {
+				if _, exists := indexes[group][task.InfoText()]; exists {
+					return nil, fmt.Errorf("internal error: duplicate task '%s'", TaskID(project, lang, task))
+				}
+				indexes[group][task.InfoText()] = len(nodes)
+				nodes = append(nodes, &node{project: project, lang: lang, task: task})
+			}
+		}
+	}
+
+	for i, n := range nodes {
+		for _, dep := range n.task.ToolInfo().DependsOn {
+			depIndex, found := indexes[groupKey(n.project, n.lang)][dep]
+			if !found {
+				return nil, fmt.Errorf(
+					"internal error: task '%s' depends on unknown task '%s'",
+					TaskID(n.project, n.lang, n.task), dep,
+				)
+			}
+			nodes[depIndex].dependents = append(nodes[depIndex].dependents, i)
+			n.waitingOn++
+		}
+	}
+
+	// Walk the graph once without running anything, to catch cycles before any Task starts
+	waitingOn := make([]int, len(nodes))
+	ready := make([]int, 0)
+	for i, n := range nodes {
+		waitingOn[i] = n.waitingOn
+		if n.waitingOn == 0 {
+			ready = append(ready, i)
+		}
+	}
+	visited := 0
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		visited++
+		for _, dependent := range nodes[i].dependents {
+			waitingOn[dependent]--
+			if waitingOn[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if visited != len(nodes) {
+		return nil, errors.New("internal error: task dependencies contain a cycle")
+	}
+
+	return nodes, nil
+}
+
+// Execute runs every Task in the [ProjectTaskMap], with each Task's commands run from its project's
+// root directory (see [system.WithWorkDir]). Each Task is canceled if it runs for longer than its
+// timeout (see [Run.taskTimeout]), and if ctx is canceled (e.g. by an interrupt), any Tasks that
+// are running are canceled too, and the rest are failed without being started. Tasks wait for their
+// dependencies (see [Tool.DependsOn]), and otherwise independent Tasks run concurrently, with up to
+// [RunOptions.Jobs] running at once. Tasks skipped per [RunOptions.Only] & [RunOptions.Skip] are
+// not run at all (nor are their hooks), but any Tasks that depend on them still run. If the [Run]
+// has a [Run.Cache], Tasks that are found in it are reported as passed without being run either,
+// and Tasks that pass are added to it.
+//
+// report is called once per Task with its [Result]. Regardless of the order that Tasks actually
+// finish in, report is always called in the same order that a sequential run would use, and always
+// from the calling goroutine, so callers don't need to worry about interleaved output. The same
+// results are also returned, in that same order, and so are grouped by project.
+//
+// The returned error is only for problems with the run itself -- Task failures are reported via
+// each [Result].
+func (run Run) Execute(
+	ctx context.Context, projectTaskMap ProjectTaskMap, hooks TaskHooks, report func(Result),
+) ([]Result, error) {
+	nodes, err := buildGraph(projectTaskMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var cacheKeys []string
+	var inputHashes map[string]string
+	if run.Cache != nil {
+		inputHashes = run.projectInputHashes(projectTaskMap.SortedKeys())
+		cacheKeys = run.cacheKeys(nodes, inputHashes)
+	}
+
+	jobs := max(run.Options.Jobs, 1)
+
+	type finished struct {
+		index  int
+		result Result
+	}
+	done := make(chan finished)
+
+	ready := make([]int, 0)
+	for i, n := range nodes {
+		if n.waitingOn == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	results := make([]*Result, len(nodes))
+	running := 0
+	finishedCount := 0
+	nextToReport := 0
+	lastProject := ""
+	lastLang := ""
+	// Only bother printing project banners if there's more than one project to tell apart
+	multiProject := len(projectTaskMap.SortedKeys()) > 1
+
+	// complete records a finished Task, queues up any of its dependents that are now ready, and
+	// reports every result that can be reported so far
+	complete := func(index int, result Result) {
+		finishedCount++
+		results[index] = &result
+
+		for _, dependent := range nodes[index].dependents {
+			nodes[dependent].waitingOn--
+			if nodes[dependent].waitingOn == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+		// Prefer starting Tasks in their listed order, when there's a choice
+		slices.Sort(ready)
+
+		for nextToReport < len(results) && results[nextToReport] != nil {
+			result := *results[nextToReport]
+			if result.Project != lastProject {
+				if multiProject {
+					run.PrintProjectBanner(result.Project)
+				}
+				lastProject = result.Project
+				lastLang = ""
+			}
+			if result.Lang != lastLang {
+				run.PrintTaskMapBanner(result.Lang)
+				lastLang = result.Lang
+			}
+			report(result)
+			nextToReport++
+		}
+	}
+
+	for finishedCount < len(nodes) {
+		for running < jobs && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+
+			if run.Options.Skips(nodes[i].lang, nodes[i].task) {
+				now := time.Now()
+				complete(i, Result{
+					Project:   nodes[i].project,
+					Lang:      nodes[i].lang,
+					Task:      nodes[i].task,
+					StartTime: now,
+					EndTime:   now,
+					Status:    StatusSkipped,
+				})
+				continue
+			}
+
+			if ctx.Err() != nil {
+				now := time.Now()
+				complete(i, Result{
+					Project:   nodes[i].project,
+					Lang:      nodes[i].lang,
+					Task:      nodes[i].task,
+					StartTime: now,
+					EndTime:   now,
+					Status:    StatusFailed,
+					Err:       fmt.Errorf("not started, since the run was canceled: %w", ctx.Err()),
+				})
+				continue
+			}
+
+			if cacheKeys != nil && cacheKeys[i] != "" && run.Cache.Has(cacheKeys[i]) {
+				now := time.Now()
+				complete(i, Result{
+					Project:   nodes[i].project,
+					Lang:      nodes[i].lang,
+					Task:      nodes[i].task,
+					StartTime: now,
+					EndTime:   now,
+					Status:    StatusPassed,
+					Cached:    true,
+				})
+				continue
+			}
+
+			running++
+			timeout := run.taskTimeout(nodes[i].lang, nodes[i].task)
+			go func() {
+				done <- finished{index: i, result: run.runTask(ctx, nodes[i], hooks, timeout)}
+			}()
+		}
+
+		if running == 0 {
+			// Any ready Tasks were all skipped or cached, so there's nothing to wait on
+			continue
+		}
+
+		f := <-done
+		running--
+		complete(f.index, f.result)
+	}
+
+	out := make([]Result, len(results))
+	for i, result := range results {
+		out[i] = *result
+	}
+
+	if run.Cache != nil {
+		run.storeCacheEntries(out, cacheKeys, inputHashes)
+	}
+
+	return out, nil
+}
+
+// runTask runs a single Task and its hooks, and returns its [Result]. The Task's Exec is canceled
+// after timeout, unless it's zero. Its Post & the Finish hook always run in full, even if the Task
+// was canceled, since they clean up after it.
+func (run Run) runTask(ctx context.Context, n *node, hooks TaskHooks, timeout time.Duration) Result {
+	id := TaskID(n.project, n.lang, n.task)
+	result := Result{
+		Project:   n.project,
+		Lang:      n.lang,
+		Task:      n.task,
+		StartTime: time.Now(),
+	}
+
+	if hooks.Start != nil {
+		hooks.Start(id, n.task.ToolInfo().Mutating)
+	}
+
+	output := run.newTaskOutput(id)
+	taskCtx := system.WithWorkDir(system.WithOutput(ctx, output), n.project)
+
+	execCtx := taskCtx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(taskCtx, timeout)
+		defer cancel()
+	}
+
+	var err error
+	err = errors.Join(err, n.task.Exec(execCtx))
+	timedOut := errors.Is(execCtx.Err(), context.DeadlineExceeded)
+	canceled := ctx.Err() != nil
+
+	err = errors.Join(err, n.task.Post(context.WithoutCancel(taskCtx)))
+
+	if hooks.Finish != nil {
+		err = errors.Join(err, hooks.Finish(context.WithoutCancel(ctx), id))
+	}
+
+	if closeErr := output.Close(); closeErr != nil {
+		iprint.Warnf("'%s': %v\n", id, closeErr)
+	}
+
+	switch {
+	case timedOut:
+		err = errors.Join(fmt.Errorf("timed out after %s", timeout), err)
+	case canceled:
+		err = errors.Join(fmt.Errorf("canceled: %w", ctx.Err()), err)
+	}
+
+	result.EndTime = time.Now()
+	result.Err = err
+	result.Output = output.String()
+	result.LogFile = output.LogFilePath()
+	switch {
+	case timedOut:
+		result.Status = StatusTimedOut
+	case err != nil:
+		result.Status = StatusFailed
+	default:
+		result.Status = StatusPassed
+	}
+
+	return result
+}
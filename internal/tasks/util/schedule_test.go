@@ -0,0 +1,311 @@
+package taskutil
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensourcecorp/oscar/internal/cache"
+	"github.com/opensourcecorp/oscar/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTask is a [Tasker] that records when it ran.
+type fakeTask struct {
+	Tool
+	name string
+	// Shared log of task names, appended to as each task finishes.
+	log *[]string
+	mu  *sync.Mutex
+	// How long Exec should block for.
+	sleep time.Duration
+}
+
+func (t fakeTask) InfoText() string { return t.name }
+
+func (t fakeTask) Exec(_ context.Context) error {
+	time.Sleep(t.sleep)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*t.log = append(*t.log, t.name)
+	return nil
+}
+
+func (t fakeTask) Post(_ context.Context) error { return nil }
+
+func TestExecute(t *testing.T) {
+	tt := []struct {
+		Name string
+		Jobs int
+	}{
+		{Name: "sequential", Jobs: 1},
+		{Name: "concurrent", Jobs: 4},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			var (
+				log []string
+				mu  sync.Mutex
+			)
+			newTask := func(name string, sleep time.Duration, deps ...string) fakeTask {
+				return fakeTask{
+					Tool:  Tool{DependsOn: deps},
+					name:  name,
+					log:   &log,
+					mu:    &mu,
+					sleep: sleep,
+				}
+			}
+
+			taskMap := TaskMap{
+				"B": {
+					newTask("format", 20*time.Millisecond),
+					newTask("lint", 0, "format"),
+				},
+				"A": {
+					newTask("slow", 30*time.Millisecond),
+				},
+			}
+
+			reported := make([]string, 0)
+			run := Run{Options: RunOptions{Jobs: s.Jobs}}
+			_, err := run.Execute(context.Background(), ProjectTaskMap{RootProject: taskMap}, TaskHooks{}, func(r Result) {
+				reported = append(reported, r.ID())
+			})
+			require.NoError(t, err)
+
+			// Reporting order must always match sequential order, no matter the finish order
+			assert.Equal(t, []string{"A :: slow", "B :: format", "B :: lint"}, reported)
+			// Dependencies must always finish first
+			assert.Less(t, slices.Index(log, "format"), slices.Index(log, "lint"))
+		})
+	}
+}
+
+func TestExecuteSkipped(t *testing.T) {
+	var (
+		log []string
+		mu  sync.Mutex
+	)
+
+	taskMap := TaskMap{
+		"A": {
+			fakeTask{name: "format", log: &log, mu: &mu},
+			fakeTask{name: "lint", log: &log, mu: &mu, Tool: Tool{DependsOn: []string{"format"}}},
+		},
+	}
+
+	run := Run{Options: RunOptions{Jobs: 2, Skip: []string{"A::format"}}}
+	results, err := run.Execute(context.Background(), ProjectTaskMap{RootProject: taskMap}, TaskHooks{}, func(_ Result) {})
+	require.NoError(t, err)
+
+	// Dependents of a skipped Task still run
+	assert.Equal(t, []string{"lint"}, log)
+	require.Len(t, results, 2)
+	assert.Equal(t, StatusSkipped, results[0].Status)
+	assert.Equal(t, StatusPassed, results[1].Status)
+}
+
+func TestBuildGraphErrors(t *testing.T) {
+	tt := []struct {
+		Name    string
+		TaskMap TaskMap
+	}{
+		{
+			Name: "unknown dependency",
+			TaskMap: TaskMap{
+				"A": {fakeTask{name: "a", Tool: Tool{DependsOn: []string{"nope"}}}},
+			},
+		},
+		{
+			Name: "cycle",
+			TaskMap: TaskMap{
+				"A": {
+					fakeTask{name: "a", Tool: Tool{DependsOn: []string{"b"}}},
+					fakeTask{name: "b", Tool: Tool{DependsOn: []string{"a"}}},
+				},
+			},
+		},
+		{
+			Name: "duplicate",
+			TaskMap: TaskMap{
+				"A": {fakeTask{name: "a"}, fakeTask{name: "a"}},
+			},
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			_, err := buildGraph(ProjectTaskMap{RootProject: s.TaskMap})
+			assert.Error(t, err)
+		})
+	}
+}
+
+// workDirTask is a [Tasker] that records the working directory it was run with.
+type workDirTask struct {
+	Tool
+	name   string
+	gotDir *string
+}
+
+func (t workDirTask) InfoText() string { return t.name }
+
+func (t workDirTask) Exec(ctx context.Context) error {
+	*t.gotDir = system.WorkDir(ctx)
+	return nil
+}
+
+func (t workDirTask) Post(_ context.Context) error { return nil }
+
+func TestExecuteProjects(t *testing.T) {
+	var rootDir, apiDir, libDir string
+
+	projectTaskMap := ProjectTaskMap{
+		"services/api": {"Python": {workDirTask{name: "lint", gotDir: &apiDir}}},
+		"lib":          {"Go": {workDirTask{name: "build", gotDir: &libDir}}},
+		RootProject:    {"Go": {workDirTask{name: "build", gotDir: &rootDir}}},
+	}
+
+	reported := make([]string, 0)
+	run := Run{Options: RunOptions{Jobs: 2}}
+	results, err := run.Execute(context.Background(), projectTaskMap, TaskHooks{}, func(r Result) {
+		reported = append(reported, r.ID())
+	})
+	require.NoError(t, err)
+
+	// Same-named Tasks in different projects must not collide, and the root project comes first
+	assert.Equal(t, []string{"Go :: build", "lib :: Go :: build", "services/api :: Python :: lint"}, reported)
+	require.Len(t, results, 3)
+	assert.Equal(t, "lib", results[1].Project)
+
+	// Each Task runs from its own project's root
+	assert.Equal(t, ".", rootDir)
+	assert.Equal(t, "lib", libDir)
+	assert.Equal(t, "services/api", apiDir)
+}
+
+func TestExecuteCached(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("main.go", []byte("package main\n"), 0644))
+
+	var (
+		log []string
+		mu  sync.Mutex
+	)
+	taskMap := TaskMap{
+		"Go": {
+			fakeTask{name: "build", log: &log, mu: &mu},
+			fakeTask{name: "breaking", log: &log, mu: &mu, Tool: Tool{Uncacheable: true}},
+		},
+	}
+
+	run := Run{
+		Options:  RunOptions{Jobs: 1},
+		Projects: []Project{{Root: RootProject, Repo: Repo{Files: []string{"main.go"}}}},
+		Cache:    cache.New(t.TempDir()),
+	}
+	execute := func() []Result {
+		projectTaskMap := ProjectTaskMap{RootProject: taskMap}
+		results, err := run.Execute(context.Background(), projectTaskMap, TaskHooks{}, func(_ Result) {})
+		require.NoError(t, err)
+		return results
+	}
+
+	execute()
+	assert.Equal(t, []string{"build", "breaking"}, log)
+
+	// Unchanged inputs are served from the cache, except for Tasks that opt out
+	log = nil
+	results := execute()
+	assert.Equal(t, []string{"breaking"}, log)
+	assert.True(t, results[0].Cached)
+	assert.Equal(t, StatusPassed, results[0].Status)
+	assert.False(t, results[1].Cached)
+
+	// Changed inputs run again
+	require.NoError(t, os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+	log = nil
+	execute()
+	assert.Equal(t, []string{"build", "breaking"}, log)
+}
+
+// blockingTask is a [Tasker] that runs until its context is canceled, and records whether its Post
+// ran.
+type blockingTask struct {
+	Tool
+	name   string
+	posted *bool
+}
+
+func (t blockingTask) InfoText() string { return t.name }
+
+func (t blockingTask) Exec(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (t blockingTask) Post(ctx context.Context) error {
+	*t.posted = true
+	return ctx.Err()
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	var hungPosted, otherPosted bool
+	taskMap := TaskMap{
+		"Go": {
+			blockingTask{name: "hung", posted: &hungPosted, Tool: Tool{Timeout: 10 * time.Millisecond}},
+			blockingTask{name: "overridden", posted: &otherPosted, Tool: Tool{Timeout: time.Hour}},
+		},
+	}
+
+	run := Run{
+		Options:      RunOptions{Jobs: 2},
+		TaskTimeouts: map[string]time.Duration{"go::overridden": 10 * time.Millisecond},
+	}
+	results, err := run.Execute(t.Context(), ProjectTaskMap{RootProject: taskMap}, TaskHooks{}, func(_ Result) {})
+	require.NoError(t, err)
+
+	for _, result := range results {
+		assert.Equal(t, StatusTimedOut, result.Status, result.ID())
+		assert.ErrorContains(t, result.Err, "timed out after 10ms", result.ID())
+	}
+	// Post always runs, and isn't canceled itself
+	assert.True(t, hungPosted)
+	assert.True(t, otherPosted)
+}
+
+func TestExecuteCanceled(t *testing.T) {
+	var (
+		posted bool
+		log    []string
+		mu     sync.Mutex
+	)
+	taskMap := TaskMap{
+		"Go": {
+			blockingTask{name: "running", posted: &posted},
+			fakeTask{name: "waiting", log: &log, mu: &mu, Tool: Tool{DependsOn: []string{"running"}}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	run := Run{Options: RunOptions{Jobs: 1}}
+	results, err := run.Execute(ctx, ProjectTaskMap{RootProject: taskMap}, TaskHooks{}, func(_ Result) {})
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.ErrorIs(t, results[0].Err, context.Canceled)
+	assert.True(t, posted)
+
+	// Tasks that hadn't started yet never do
+	assert.Equal(t, StatusFailed, results[1].Status)
+	assert.Empty(t, log)
+}
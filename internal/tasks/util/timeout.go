@@ -0,0 +1,66 @@
+package taskutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTaskTimeout is how long a Task may run before it's canceled, unless it sets its own
+// [Tool.Timeout].
+const DefaultTaskTimeout = 10 * time.Minute
+
+// parseTaskTimeouts parses the task timeouts from the oscar config file, keyed by task filter (see
+// [RunOptions.Only]). A zero duration means no timeout.
+func parseTaskTimeouts(cfgTimeouts map[string]string) (map[string]time.Duration, error) {
+	out := make(map[string]time.Duration)
+
+	var errs error
+	for filter, value := range cfgTimeouts {
+		if err := ValidateTaskFilter(filter); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			errs = errors.Join(errs, fmt.Errorf(
+				"timeout '%s' for '%s' must be a positive duration like '90s' or '1h', or '0' for no timeout",
+				value, filter,
+			))
+			continue
+		}
+		out[filter] = timeout
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	return out, nil
+}
+
+// taskTimeout returns how long the provided Task, listed under lang in its [TaskMap], may run. An
+// entry in [Run.TaskTimeouts] for the Task itself takes precedence over one for its whole group,
+// which takes precedence over the Task's own [Tool.Timeout]. It returns zero for no timeout.
+func (run Run) taskTimeout(lang string, task Tasker) time.Duration {
+	groupTimeout, groupFound := time.Duration(0), false
+	for filter, timeout := range run.TaskTimeouts {
+		if !matchesTaskFilter(filter, lang, task) {
+			continue
+		}
+		if strings.Contains(filter, filterSeparator) {
+			return timeout
+		}
+		groupTimeout, groupFound = timeout, true
+	}
+
+	switch {
+	case groupFound:
+		return groupTimeout
+	case task.ToolInfo().Timeout > 0:
+		return task.ToolInfo().Timeout
+	default:
+		return DefaultTaskTimeout
+	}
+}
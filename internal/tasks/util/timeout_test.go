@@ -0,0 +1,53 @@
+package taskutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskTimeout(t *testing.T) {
+	run := Run{
+		TaskTimeouts: map[string]time.Duration{
+			"Go":         time.Hour,
+			"Go::Test":   2 * time.Hour,
+			"YAML::Lint": 0,
+		},
+	}
+
+	tt := []struct {
+		Name string
+		Lang string
+		Task Tasker
+		Want time.Duration
+	}{
+		{Name: "default", Lang: "Python", Task: fakeTask{name: "Lint"}, Want: DefaultTaskTimeout},
+		{Name: "task's own", Lang: "Python", Task: fakeTask{name: "Lint", Tool: Tool{Timeout: time.Minute}}, Want: time.Minute},
+		{Name: "group override", Lang: "Go", Task: fakeTask{name: "Vet", Tool: Tool{Timeout: time.Minute}}, Want: time.Hour},
+		{Name: "task override", Lang: "Go", Task: fakeTask{name: "Test"}, Want: 2 * time.Hour},
+		{Name: "no timeout", Lang: "YAML", Task: fakeTask{name: "Lint"}, Want: 0},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			assert.Equal(t, s.Want, run.taskTimeout(s.Lang, s.Task))
+		})
+	}
+}
+
+func TestParseTaskTimeouts(t *testing.T) {
+	got, err := parseTaskTimeouts(map[string]string{"Go": "90s", "Go::Test": "0"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"Go": 90 * time.Second, "Go::Test": 0}, got)
+
+	for _, bad := range []map[string]string{
+		{"Go": "soon"},
+		{"Go": "-1m"},
+		{"::Test": "1m"},
+	} {
+		_, err := parseTaskTimeouts(bad)
+		assert.Error(t, err, bad)
+	}
+}
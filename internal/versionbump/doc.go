@@ -0,0 +1,2 @@
+// Package versionbump increments the version in oscar config files.
+package versionbump
@@ -0,0 +1,268 @@
+package versionbump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opensourcecorp/oscar/internal/consts"
+	igit "github.com/opensourcecorp/oscar/internal/git"
+	"github.com/opensourcecorp/oscar/internal/oscarcfg"
+	iprint "github.com/opensourcecorp/oscar/internal/print"
+)
+
+// Level is which part of a version to increment.
+type Level string
+
+const (
+	// LevelMajor increments the major version, e.g. "1.2.3" -> "2.0.0".
+	LevelMajor Level = "major"
+	// LevelMinor increments the minor version, e.g. "1.2.3" -> "1.3.0".
+	LevelMinor Level = "minor"
+	// LevelPatch increments the patch version, e.g. "1.2.3" -> "1.2.4".
+	LevelPatch Level = "patch"
+	// LevelPrerelease increments the prerelease identifier, e.g. "1.2.3-alpha1" -> "1.2.3-alpha2".
+	LevelPrerelease Level = "prerelease"
+)
+
+// Levels lists every valid [Level].
+var Levels = []Level{LevelMajor, LevelMinor, LevelPatch, LevelPrerelease}
+
+// defaultPrerelease is the prerelease identifier used when bumping the prerelease of a version that
+// doesn't have one yet.
+const defaultPrerelease = "alpha1"
+
+// versionRegex matches the versions allowed in oscar config files, with groups for the major,
+// minor, patch, and prerelease parts. Any build metadata is matched, but not kept.
+var versionRegex = regexp.MustCompile(`^([0-9]+)\.([0-9]+)\.([0-9]+)(?:-([a-zA-Z0-9]+))?(?:\+[a-zA-Z0-9]+)?$`)
+
+// Options holds caller-provided settings for [Run].
+type Options struct {
+	// Which part of the version to increment. Must be empty if FromCommits is set.
+	Level string
+	// Whether to derive the [Level] from the Conventional Commit messages since the latest tag,
+	// instead of using Level. See [LevelFromCommits].
+	FromCommits bool
+}
+
+// Run increments the version in the oscar config file, relative to the version on the base branch
+// if it can be found (since that's what the CI version check compares against). If the
+// version has already been incremented at least that far, nothing is changed. Only the version
+// itself is rewritten, so the rest of the file is left exactly as it was.
+func Run(ctx context.Context, opts Options) error {
+	level, err := resolveLevel(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	path := consts.DefaultOscarCfgFileName
+	cfgData, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading oscar config file: %w", err)
+	}
+	cfg, err := oscarcfg.Parse(cfgData)
+	if err != nil {
+		return fmt.Errorf("oscar config file '%s': %w", path, err)
+	}
+	current := cfg.GetVersion()
+
+	base := current
+	baseRef, err := igit.BaseBranchRef(ctx, oscarcfg.BaseBranch(cfg))
+	if err != nil {
+		iprint.Warnf("%v, so bumping from the current version instead\n", err)
+	} else {
+		baseCfg, err := oscarcfg.GetAtRef(ctx, baseRef)
+		if err != nil {
+			return err
+		}
+		iprint.Infof("Version on '%s': %s\n", baseRef, baseCfg.GetVersion())
+		base = baseCfg.GetVersion()
+	}
+	iprint.Infof("Version on this branch: %s\n", current)
+
+	next, err := Bump(base, level)
+	if err != nil {
+		return err
+	}
+
+	if current != base && !oscarcfg.VersionHasBeenIncremented(next, current) {
+		iprint.Goodf("Version has already been incremented to %s, nothing to do\n", current)
+		return nil
+	}
+
+	out, err := oscarcfg.SetVersion(cfgData, next)
+	if err != nil {
+		return fmt.Errorf("updating version in oscar config file: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("writing oscar config file: %w", err)
+	}
+
+	iprint.Goodf("Bumped %s version: %s -> %s\n", level, current, next)
+
+	return nil
+}
+
+// resolveLevel returns the [Level] to bump by, per the provided [Options].
+func resolveLevel(ctx context.Context, opts Options) (Level, error) {
+	if opts.FromCommits {
+		if opts.Level != "" {
+			return "", errors.New("cannot provide a bump level when deriving it from commits")
+		}
+
+		tag := igit.LatestReachableTag(ctx)
+		messages, err := igit.CommitMessagesSince(ctx, tag)
+		if err != nil {
+			return "", err
+		}
+
+		since := "the first commit"
+		if tag != "" {
+			since = "tag '" + tag + "'"
+		}
+
+		level, found := LevelFromCommits(messages)
+		if !found {
+			return "", fmt.Errorf(
+				"none of the %d commit(s) since %s are features, fixes, or breaking changes -- provide a bump level explicitly",
+				len(messages), since,
+			)
+		}
+		iprint.Infof("Using bump level '%s', based on %d commit(s) since %s\n", level, len(messages), since)
+
+		return level, nil
+	}
+
+	return ParseLevel(opts.Level)
+}
+
+// ParseLevel returns the [Level] named by s.
+func ParseLevel(s string) (Level, error) {
+	for _, level := range Levels {
+		if strings.EqualFold(s, string(level)) {
+			return level, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown bump level '%s', must be one of %v", s, Levels)
+}
+
+// Bump returns the provided version, incremented by the provided [Level]. Any build metadata is
+// dropped.
+//
+// As with most tooling, a prerelease version is considered to come before its release, so bumping a
+// prerelease version only releases it if it's already a prerelease of that [Level] (e.g. bumping
+// the patch of "1.2.3-alpha1" gives "1.2.3", while bumping its minor version gives "1.3.0").
+// Bumping the prerelease of a version that isn't one starts a prerelease of the next patch version.
+func Bump(version string, level Level) (string, error) {
+	groups := versionRegex.FindStringSubmatch(version)
+	if groups == nil {
+		return "", fmt.Errorf("'%s' is not a valid version", version)
+	}
+
+	parts := make([]int, 3)
+	for i := range parts {
+		n, err := strconv.Atoi(groups[i+1])
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not a valid version: %w", version, err)
+		}
+		parts[i] = n
+	}
+	major, minor, patch, prerelease := parts[0], parts[1], parts[2], groups[4]
+
+	switch level {
+	case LevelMajor:
+		if prerelease == "" || minor != 0 || patch != 0 {
+			major, minor, patch = major+1, 0, 0
+		}
+		prerelease = ""
+	case LevelMinor:
+		if prerelease == "" || patch != 0 {
+			minor, patch = minor+1, 0
+		}
+		prerelease = ""
+	case LevelPatch:
+		if prerelease == "" {
+			patch++
+		}
+		prerelease = ""
+	case LevelPrerelease:
+		if prerelease == "" {
+			patch++
+			prerelease = defaultPrerelease
+		} else {
+			prerelease = bumpPrerelease(prerelease)
+		}
+	default:
+		return "", fmt.Errorf("unknown bump level '%s'", level)
+	}
+
+	out := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	if prerelease != "" {
+		out += "-" + prerelease
+	}
+
+	return out, nil
+}
+
+// bumpPrerelease increments the number at the end of a prerelease identifier, e.g. "alpha2" ->
+// "alpha3". If there is no number at the end, it adds one, e.g. "alpha" -> "alpha1".
+func bumpPrerelease(prerelease string) string {
+	prefix := strings.TrimRight(prerelease, "0123456789")
+	n, err := strconv.Atoi(prerelease[len(prefix):])
+	if err != nil {
+		// No trailing number
+		return prefix + "1"
+	}
+
+	return prefix + strconv.Itoa(n+1)
+}
+
+// conventionalCommitRegex matches the header of a Conventional Commit message, with groups for its
+// type & breaking-change marker. See https://www.conventionalcommits.org.
+var conventionalCommitRegex = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?(!)?: `)
+
+// LevelFromCommits returns the [Level] implied by the provided Conventional Commit messages: major
+// if any are breaking changes, otherwise minor if any are features, otherwise patch if any are
+// fixes or performance improvements. It returns false if none of them are any of those, e.g. if
+// they're all docs or chore commits, or don't follow the Conventional Commits format at all.
+func LevelFromCommits(messages []string) (Level, bool) {
+	var out Level
+	for _, message := range messages {
+		header, body, _ := strings.Cut(message, "\n")
+		groups := conventionalCommitRegex.FindStringSubmatch(header)
+		if groups == nil {
+			continue
+		}
+
+		if groups[2] == "!" || hasBreakingChangeFooter(body) {
+			return LevelMajor, true
+		}
+
+		switch strings.ToLower(groups[1]) {
+		case "feat":
+			out = LevelMinor
+		case "fix", "perf":
+			if out == "" {
+				out = LevelPatch
+			}
+		}
+	}
+
+	return out, out != ""
+}
+
+// hasBreakingChangeFooter reports whether a commit message body has a "BREAKING CHANGE" footer.
+func hasBreakingChangeFooter(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package versionbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBump(t *testing.T) {
+	tt := []struct {
+		Version string
+		Level   Level
+		Want    string
+	}{
+		{Version: "1.2.3", Level: LevelMajor, Want: "2.0.0"},
+		{Version: "1.2.3", Level: LevelMinor, Want: "1.3.0"},
+		{Version: "1.2.3", Level: LevelPatch, Want: "1.2.4"},
+		{Version: "1.2.3", Level: LevelPrerelease, Want: "1.2.4-alpha1"},
+		{Version: "1.2.3+build5", Level: LevelPatch, Want: "1.2.4"},
+		{Version: "0.3.0-alpha2", Level: LevelPrerelease, Want: "0.3.0-alpha3"},
+		{Version: "0.3.0-rc", Level: LevelPrerelease, Want: "0.3.0-rc1"},
+		{Version: "0.3.0-alpha9", Level: LevelPrerelease, Want: "0.3.0-alpha10"},
+		// Prereleases are released by bumping to their own level...
+		{Version: "0.3.0-alpha2", Level: LevelMinor, Want: "0.3.0"},
+		{Version: "0.3.1-alpha2", Level: LevelPatch, Want: "0.3.1"},
+		{Version: "2.0.0-rc1", Level: LevelMajor, Want: "2.0.0"},
+		// ...and otherwise bumped as usual
+		{Version: "0.3.1-alpha2", Level: LevelMinor, Want: "0.4.0"},
+		{Version: "0.3.0-alpha2", Level: LevelMajor, Want: "1.0.0"},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Version+" "+string(s.Level), func(t *testing.T) {
+			got, err := Bump(s.Version, s.Level)
+			require.NoError(t, err)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+
+	t.Run("invalid version", func(t *testing.T) {
+		_, err := Bump("v1.2", LevelPatch)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("Minor")
+	require.NoError(t, err)
+	assert.Equal(t, LevelMinor, level)
+
+	_, err = ParseLevel("huge")
+	assert.Error(t, err)
+}
+
+func TestLevelFromCommits(t *testing.T) {
+	tt := []struct {
+		Name      string
+		Messages  []string
+		Want      Level
+		WantFound bool
+	}{
+		{
+			Name:      "fixes only",
+			Messages:  []string{"fix: handle nil config", "docs: update README", "perf(cache): skip rehash"},
+			Want:      LevelPatch,
+			WantFound: true,
+		},
+		{
+			Name:      "feature",
+			Messages:  []string{"fix: handle nil config", "feat(cli): add version bump"},
+			Want:      LevelMinor,
+			WantFound: true,
+		},
+		{
+			Name:      "breaking marker",
+			Messages:  []string{"feat(cli)!: rename ci subcommand"},
+			Want:      LevelMajor,
+			WantFound: true,
+		},
+		{
+			Name:      "breaking footer",
+			Messages:  []string{"fix: stop reading old config\n\nBREAKING CHANGE: old config files are ignored"},
+			Want:      LevelMajor,
+			WantFound: true,
+		},
+		{
+			Name:      "nothing releasable",
+			Messages:  []string{"chore: bump deps", "Merge branch 'main'", "feature: not a real type"},
+			WantFound: false,
+		},
+	}
+
+	for _, s := range tt {
+		t.Run(s.Name, func(t *testing.T) {
+			got, found := LevelFromCommits(s.Messages)
+			assert.Equal(t, s.WantFound, found)
+			assert.Equal(t, s.Want, got)
+		})
+	}
+}